@@ -20,6 +20,10 @@ func convertCommand() *cobra.Command {
 		covert.SQL2GormCommand(),
 		covert.JSON2StructCommand(),
 		covert.Yaml2StructCommand(),
+		covert.JSONSchema2StructCommand(),
+		covert.Toml2StructCommand(),
+		covert.XML2StructCommand(),
+		covert.Env2StructCommand(),
 	)
 
 	return cmd
@@ -0,0 +1,81 @@
+package covert
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhufuyi/gotool/pkg/jy2struct"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	covertTypeEnv2Struct        = "env"
+	covertTypeProperties2Struct = "properties"
+)
+
+// Env2StructCommand covert .env or .properties to struct command
+func Env2StructCommand() *cobra.Command {
+	var (
+		// env to struct args
+		esArgs     = jy2struct.Args{}
+		outPath    = ""
+		properties = false
+	)
+
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Covert .env or .properties to struct",
+		Long: `covert .env or .properties to struct.
+
+Keys of .env are kept as they are, keys of .properties separated by dots are sub-structs,
+files with extension .properties are parsed as properties.
+
+Examples:
+  # covert .env to struct from file
+  gotool covert env --file=.env
+
+  # covert .properties to struct from file
+  gotool covert env --file=application.properties
+
+  # covert .properties to struct from data
+  gotool covert env --data="server.port=8080" --properties
+
+  # covert .env to struct, set tag value, save to specified directory, file name is named after the struct or input file
+  gotool covert env --file=.env --tags=json --out=/tmp
+
+`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			esArgs.Format = covertTypeEnv2Struct
+			if properties || strings.EqualFold(filepath.Ext(esArgs.InputFile), ".properties") {
+				esArgs.Format = covertTypeProperties2Struct
+			}
+			name := esArgs.Name
+			out, err := jy2struct.Covert(&esArgs)
+			if err != nil {
+				return err
+			}
+
+			if outPath != "" {
+				return saveFile(esArgs.InputFile, name, outPath, esArgs.Lang, out)
+			}
+
+			fmt.Println(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&esArgs.Data, "data", "d", "", "env or properties content")
+	cmd.Flags().StringVarP(&esArgs.InputFile, "file", "f", "", "env or properties file")
+	cmd.Flags().BoolVarP(&properties, "properties", "", false, "parse as properties, keys separated by dots are sub-structs")
+	cmd.Flags().StringVarP(&esArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&esArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
+	cmd.Flags().StringVarP(&esArgs.Tags, "tags", "t", "", "specify tags in addition to env or properties, with multiple tags separated by commas")
+	cmd.Flags().BoolVarP(&esArgs.SubStruct, "sub-struct", "s", true, "create types for sub-structs (default is true)")
+	cmd.Flags().StringVarP(&esArgs.Lang, "lang", "l", "go", "language of the generated code, go or ts")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
+	return cmd
+}
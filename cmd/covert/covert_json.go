@@ -16,6 +16,7 @@ func JSON2StructCommand() *cobra.Command {
 		// json to struct args
 		jsArgs  = jy2struct.Args{}
 		outPath = ""
+		ndjson  = false
 	)
 
 	cmd := &cobra.Command{
@@ -33,7 +34,25 @@ Examples:
   # covert json to struct, set tag value
   gotool covert json --file=test.json --tags=gorm
 
-  # covert yaml to struct, save to specified directory, file name is config.go
+  # covert ndjson to struct, all documents are merged, fields missing in some documents are omitempty pointers
+  gotool covert json --file=test.ndjson --ndjson
+
+  # covert all json files in the directory to one struct
+  gotool covert json --file=/tmp/samples
+
+  # covert json to struct, set struct name and package name
+  gotool covert json --file=test.json --name=User --pkg-name=model
+
+  # covert json to struct, detect time.Time, time.Duration and uuid.UUID from string values
+  gotool covert json --file=test.json --detect=time,duration,uuid
+
+  # covert json to struct, keep the fields in the order of the json keys
+  gotool covert json --file=test.json --keep-order
+
+  # covert json to typescript interface
+  gotool covert json --file=test.json --lang=ts
+
+  # covert json to struct, save to specified directory, file name is named after the struct or input file, e.g. user.go
   gotool covert json --file=test.json --out=/tmp
 
 `,
@@ -41,13 +60,17 @@ Examples:
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			jsArgs.Format = covertTypeJSON2Struct
+			if ndjson {
+				jsArgs.Format = "ndjson"
+			}
+			name := jsArgs.Name
 			out, err := jy2struct.Covert(&jsArgs)
 			if err != nil {
 				return err
 			}
 
 			if outPath != "" {
-				return saveFile(jsArgs.InputFile, outPath, out)
+				return saveFile(jsArgs.InputFile, name, outPath, jsArgs.Lang, out)
 			}
 
 			fmt.Println(out)
@@ -56,9 +79,18 @@ Examples:
 	}
 
 	cmd.Flags().StringVarP(&jsArgs.Data, "data", "d", "", "json data")
-	cmd.Flags().StringVarP(&jsArgs.InputFile, "file", "f", "", "json file")
+	cmd.Flags().StringVarP(&jsArgs.InputFile, "file", "f", "", "json file, or a directory of json files used as samples")
+	cmd.Flags().BoolVarP(&ndjson, "ndjson", "", false, "input is newline delimited json, every line is a sample")
+	cmd.Flags().StringVarP(&jsArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&jsArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
 	cmd.Flags().StringVarP(&jsArgs.Tags, "tags", "t", "", "specify tags in addition to the format, with multiple tags separated by commas")
 	cmd.Flags().BoolVarP(&jsArgs.SubStruct, "sub-struct", "s", true, "create types for sub-structs (default is true)")
+	cmd.Flags().BoolVarP(&jsArgs.ParentPrefix, "parent-prefix", "", false, "always name sub-structs after parent struct name and key, by default, only when the key name is taken")
+	cmd.Flags().StringVarP(&jsArgs.Detect, "detect", "", "", "detect types from string values, time, duration or uuid, multiple types separated by commas")
+	cmd.Flags().StringVarP(&jsArgs.Omitempty, "omitempty", "", "optional", "fields tagged omitempty, optional(fields absent in some samples), all or none")
+	cmd.Flags().StringVarP(&jsArgs.UnknownType, "unknown-type", "", "interface{}", "type of null values and elements of mixed arrays, interface{}, any or json.RawMessage")
+	cmd.Flags().BoolVarP(&jsArgs.KeepOrder, "keep-order", "", false, "keep the fields in the order of the json keys, by default, fields are sorted by key")
+	cmd.Flags().StringVarP(&jsArgs.Lang, "lang", "l", "go", "language of the generated code, go or ts")
 	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
 	return cmd
 }
@@ -0,0 +1,64 @@
+package covert
+
+import (
+	"fmt"
+
+	"github.com/zhufuyi/gotool/pkg/jy2struct"
+
+	"github.com/spf13/cobra"
+)
+
+const covertTypeJSONSchema2Struct = "jsonschema"
+
+// JSONSchema2StructCommand covert json schema to struct command
+func JSONSchema2StructCommand() *cobra.Command {
+	var (
+		// json schema to struct args
+		jsArgs  = jy2struct.Args{}
+		outPath = ""
+	)
+
+	cmd := &cobra.Command{
+		Use:   "jsonschema",
+		Short: "Covert json schema to struct",
+		Long: `covert json schema to struct, required fields are values, optional fields are pointers with omitempty.
+
+Examples:
+  # covert json schema to struct from file
+  gotool covert jsonschema --file=user.schema.json --name=User
+
+  # covert json schema to struct, set tag value, save to specified directory, file name is named after the struct or input file
+  gotool covert jsonschema --file=user.schema.json --tags=gorm --out=/tmp
+
+`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsArgs.Format = covertTypeJSONSchema2Struct
+			name := jsArgs.Name
+			out, err := jy2struct.Covert(&jsArgs)
+			if err != nil {
+				return err
+			}
+
+			if outPath != "" {
+				return saveFile(jsArgs.InputFile, name, outPath, jsArgs.Lang, out)
+			}
+
+			fmt.Println(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&jsArgs.Data, "data", "d", "", "json schema data")
+	cmd.Flags().StringVarP(&jsArgs.InputFile, "file", "f", "", "json schema file")
+	cmd.Flags().StringVarP(&jsArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&jsArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
+	cmd.Flags().StringVarP(&jsArgs.Tags, "tags", "t", "", "specify tags in addition to json, with multiple tags separated by commas")
+	cmd.Flags().BoolVarP(&jsArgs.SubStruct, "sub-struct", "s", true, "create types for nested objects, referenced objects are always types (default is true)")
+	cmd.Flags().BoolVarP(&jsArgs.ParentPrefix, "parent-prefix", "", false, "always name nested types after parent struct name and key, by default, only when the key name is taken")
+	cmd.Flags().StringVarP(&jsArgs.Omitempty, "omitempty", "", "optional", "fields tagged omitempty, optional(fields not required), all or none")
+	cmd.Flags().StringVarP(&jsArgs.UnknownType, "unknown-type", "", "interface{}", "type of values without type and mixed oneOf, interface{}, any or json.RawMessage")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
+	return cmd
+}
@@ -30,10 +30,13 @@ Examples:
   # covert mysql table gorm model code
   gotool covert sql --db-dsn=root:123456@(192.168.3.37:3306)/test --db-table=user
 
+  # covert mysql table gorm model code, read table schema from information_schema
+  gotool covert sql --db-dsn=root:123456@(192.168.3.37:3306)/test --db-table=user --db-loader=information_schema --db-timeout=10s
+
   # covert mysql table, structure fields correspond to the column names of the table.
   gotool covert sql --db-dsn=root:123456@(192.168.3.37:3306)/test --db-table=user --embed=false
 
-  # covert mysql table to handler request and respond struct code,  other type json or dao
+  # covert mysql table to handler request and respond struct code,  other type json, dao, proto, service or typescript
   gotool covert sql --db-dsn=root:123456@(192.168.3.37:3306)/test --db-table=user --code-type=handler
 
   # covert sql file to gorm model code and add json tag
@@ -58,8 +61,10 @@ Examples:
 	cmd.Flags().StringVarP(&sqlArgs.DDLFile, "file", "f", "", "input DDL sql file")
 	cmd.Flags().StringVarP(&sqlArgs.DBDsn, "db-dsn", "d", "", "db content addr, E.g. user:password@(host:port)/database")
 	cmd.Flags().StringVarP(&sqlArgs.DBTable, "db-table", "t", "", "table name")
+	cmd.Flags().StringVarP(&sqlArgs.DBLoader, "db-loader", "", "ddl", "the way to read table schema from db, ddl(SHOW CREATE TABLE) or information_schema")
+	cmd.Flags().DurationVarP(&sqlArgs.DBTimeout, "db-timeout", "", 0, "timeout of reading table schema from db, e.g. 10s, 0 means no timeout")
 	cmd.Flags().StringVarP(&sqlArgs.Package, "pkg-name", "p", "", "package name")
-	cmd.Flags().StringVarP(&sqlArgs.CodeType, "code-type", "c", "model", "specify the use of the generated code, support 4 types, model(default), json, dao, handler")
+	cmd.Flags().StringVarP(&sqlArgs.CodeType, "code-type", "c", "model", "specify the use of the generated code, support model(default), json, dao, handler, proto, service, typescript")
 	cmd.Flags().BoolVarP(&sqlArgs.JSONTag, "json-tag", "j", false, "whether to generate json tag")
 	cmd.Flags().BoolVarP(&sqlArgs.IsEmbed, "embed", "e", true, "whether to embed 'gorm.Model' struct")
 	cmd.Flags().IntVarP(&sqlArgs.JSONNamedType, "json-named-type", "J", 0, "json named type, 0:snake_case, other:camelCase")
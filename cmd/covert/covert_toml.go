@@ -0,0 +1,65 @@
+package covert
+
+import (
+	"fmt"
+
+	"github.com/zhufuyi/gotool/pkg/jy2struct"
+
+	"github.com/spf13/cobra"
+)
+
+const covertTypeToml2Struct = "toml"
+
+// Toml2StructCommand covert toml to struct command
+func Toml2StructCommand() *cobra.Command {
+	var (
+		// toml to struct args
+		tsArgs  = jy2struct.Args{}
+		outPath = ""
+	)
+
+	cmd := &cobra.Command{
+		Use:   "toml",
+		Short: "Covert toml to struct",
+		Long: `covert toml to struct.
+
+Examples:
+  # covert toml to struct from data
+  gotool covert toml --data="toml text"
+
+  # covert toml to struct from file
+  gotool covert toml --file=config.toml
+
+  # covert toml to struct, set tag value, save to specified directory, file name is named after the struct or input file
+  gotool covert toml --file=config.toml --tags=json --out=/tmp
+
+`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tsArgs.Format = covertTypeToml2Struct
+			name := tsArgs.Name
+			out, err := jy2struct.Covert(&tsArgs)
+			if err != nil {
+				return err
+			}
+
+			if outPath != "" {
+				return saveFile(tsArgs.InputFile, name, outPath, tsArgs.Lang, out)
+			}
+
+			fmt.Println(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&tsArgs.Data, "data", "d", "", "toml content")
+	cmd.Flags().StringVarP(&tsArgs.InputFile, "file", "f", "", "toml file")
+	cmd.Flags().StringVarP(&tsArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&tsArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
+	cmd.Flags().StringVarP(&tsArgs.Tags, "tags", "t", "", "specify tags in addition to toml, with multiple tags separated by commas")
+	cmd.Flags().BoolVarP(&tsArgs.SubStruct, "sub-struct", "s", true, "create types for sub-structs (default is true)")
+	cmd.Flags().StringVarP(&tsArgs.Lang, "lang", "l", "go", "language of the generated code, go or ts")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
+	return cmd
+}
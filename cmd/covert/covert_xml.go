@@ -0,0 +1,68 @@
+package covert
+
+import (
+	"fmt"
+
+	"github.com/zhufuyi/gotool/pkg/jy2struct"
+
+	"github.com/spf13/cobra"
+)
+
+const covertTypeXML2Struct = "xml"
+
+// XML2StructCommand covert xml to struct command
+func XML2StructCommand() *cobra.Command {
+	var (
+		// xml to struct args
+		xsArgs  = jy2struct.Args{}
+		outPath = ""
+	)
+
+	cmd := &cobra.Command{
+		Use:   "xml",
+		Short: "Covert xml to struct",
+		Long: `covert xml to struct.
+
+Attributes are tagged attr, text of elements with attributes or children is tagged chardata,
+repeated elements are slices.
+
+Examples:
+  # covert xml to struct from data
+  gotool covert xml --data="xml text"
+
+  # covert xml to struct from file
+  gotool covert xml --file=config.xml
+
+  # covert xml to struct, set tag value, save to specified directory, file name is named after the struct or input file
+  gotool covert xml --file=config.xml --tags=json --out=/tmp
+
+`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			xsArgs.Format = covertTypeXML2Struct
+			name := xsArgs.Name
+			out, err := jy2struct.Covert(&xsArgs)
+			if err != nil {
+				return err
+			}
+
+			if outPath != "" {
+				return saveFile(xsArgs.InputFile, name, outPath, xsArgs.Lang, out)
+			}
+
+			fmt.Println(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&xsArgs.Data, "data", "d", "", "xml content")
+	cmd.Flags().StringVarP(&xsArgs.InputFile, "file", "f", "", "xml file")
+	cmd.Flags().StringVarP(&xsArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&xsArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
+	cmd.Flags().StringVarP(&xsArgs.Tags, "tags", "t", "", "specify tags in addition to xml, with multiple tags separated by commas")
+	cmd.Flags().BoolVarP(&xsArgs.SubStruct, "sub-struct", "s", true, "create types for sub-structs (default is true)")
+	cmd.Flags().StringVarP(&xsArgs.Lang, "lang", "l", "go", "language of the generated code, go or ts")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
+	return cmd
+}
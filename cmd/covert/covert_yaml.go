@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode"
 
 	"github.com/zhufuyi/gotool/pkg/gofile"
 	"github.com/zhufuyi/gotool/pkg/jy2struct"
 
+	"github.com/huandu/xstrings"
 	"github.com/spf13/cobra"
 )
 
@@ -30,10 +33,25 @@ Examples:
   # covert yaml to struct from data
   gotool covert yaml --data="yaml text"
 
-  # covert yaml to struct from file
+  # covert yaml to struct from file, comments are kept as field comments
   gotool covert yaml --file=test.yaml
 
-  # covert yaml to struct, set tag value, save to specified directory, file name is config.go
+  # covert yaml to struct, set struct name and package name
+  gotool covert yaml --file=test.yaml --name=Config --pkg-name=config
+
+  # covert yaml to struct, fields are in the order of the yaml instead of sorted by key
+  gotool covert yaml --file=test.yaml --keep-order
+
+  # covert yaml to struct, detect time.Time, time.Duration and uuid.UUID from string values
+  gotool covert yaml --file=test.yaml --detect=time,duration,uuid
+
+  # covert yaml to struct and Load function, default values are taken from the yaml, overridden by env such as APP_SERVER_PORT
+  gotool covert yaml --file=config.yaml --name=Config --pkg-name=config --loader --env-prefix=APP
+
+  # covert yaml to typescript interface
+  gotool covert yaml --file=test.yaml --lang=ts
+
+  # covert yaml to struct, set tag value, save to specified directory, file name is named after the struct or input file
   gotool covert yaml --file=test.yaml --tags=json --out=/tmp
 
 `,
@@ -41,13 +59,14 @@ Examples:
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ysArgs.Format = covertTypeYaml2Struct
+			name := ysArgs.Name
 			out, err := jy2struct.Covert(&ysArgs)
 			if err != nil {
 				return err
 			}
 
 			if outPath != "" {
-				return saveFile(ysArgs.InputFile, outPath, out)
+				return saveFile(ysArgs.InputFile, name, outPath, ysArgs.Lang, out)
 			}
 
 			fmt.Println(out)
@@ -57,22 +76,59 @@ Examples:
 
 	cmd.Flags().StringVarP(&ysArgs.Data, "data", "d", "", "yaml content")
 	cmd.Flags().StringVarP(&ysArgs.InputFile, "file", "f", "", "yaml file")
+	cmd.Flags().StringVarP(&ysArgs.Name, "name", "n", "", "struct name, default is GenerateName")
+	cmd.Flags().StringVarP(&ysArgs.PkgName, "pkg-name", "p", "main", "package name of the generated go code")
 	cmd.Flags().StringVarP(&ysArgs.Tags, "tags", "t", "", "struct tags, multiple tags separated by commas")
 	cmd.Flags().BoolVarP(&ysArgs.SubStruct, "sub-struct", "s", true, "create types for sub-structs (default is true)")
+	cmd.Flags().BoolVarP(&ysArgs.ParentPrefix, "parent-prefix", "", false, "always name sub-structs after parent struct name and key, by default, only when the key name is taken")
+	cmd.Flags().StringVarP(&ysArgs.Detect, "detect", "", "", "detect types from string values, time, duration or uuid, multiple types separated by commas")
+	cmd.Flags().StringVarP(&ysArgs.Omitempty, "omitempty", "", "optional", "fields tagged omitempty, optional(fields absent in some samples), all or none")
+	cmd.Flags().StringVarP(&ysArgs.UnknownType, "unknown-type", "", "interface{}", "type of null values and elements of mixed arrays, interface{}, any or json.RawMessage")
+	cmd.Flags().BoolVarP(&ysArgs.KeepOrder, "keep-order", "", false, "keep the fields in the order of the yaml keys, by default, fields are sorted by key")
+	cmd.Flags().BoolVarP(&ysArgs.Loader, "loader", "", false, "generate Load function with default values, environment variable overrides and Validate method")
+	cmd.Flags().StringVarP(&ysArgs.EnvPrefix, "env-prefix", "", "APP", "prefix of the environment variables that override the config, used with --loader")
+	cmd.Flags().StringVarP(&ysArgs.Lang, "lang", "l", "go", "language of the generated code, go or ts")
 	cmd.Flags().StringVarP(&outPath, "out", "o", "", "export the code path")
 	return cmd
 }
 
-func saveFile(inputFile string, outPath string, code string) error {
+// save the code to outPath, the file is named after the struct name or the input file, e.g. user_info.go
+func saveFile(inputFile string, name string, outPath string, lang string, code string) error {
 	abs, err := filepath.Abs(outPath)
 	if err != nil {
 		return err
 	}
-	outFile := abs + gofile.GetPathDelimiter() + "config.go"
+	outFile := abs + gofile.GetPathDelimiter() + outputFileName(inputFile, name, lang)
 	err = os.WriteFile(outFile, []byte(code), 0666)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("covert '%s' to go struct successfully, output = %s\n\n", inputFile, outFile)
+	fmt.Printf("covert '%s' successfully, output = %s\n\n", inputFile, outFile)
 	return nil
 }
+
+func outputFileName(inputFile string, name string, lang string) string {
+	ext := ".go"
+	if lang == jy2struct.LangTypeScript {
+		ext = ".ts"
+	}
+
+	if name != "" {
+		return xstrings.ToSnakeCase(name) + ext
+	}
+
+	base := filepath.Base(inputFile)
+	if inputFile == "" || base == "." || base == string(filepath.Separator) {
+		return "config" + ext
+	}
+	if trimmed := strings.TrimSuffix(base, filepath.Ext(base)); trimmed != "" {
+		base = trimmed // e.g. user.json -> user
+	}
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(base, ".")) // e.g. .env -> env, user.schema -> user_schema
+	return base + ext
+}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/zhufuyi/gotool/pkg/replacer"
 
@@ -14,6 +17,7 @@ func replaceCommand() *cobra.Command {
 		srcPath  string   // 源目录
 		oldValue []string // 旧字段
 		newValue []string // 新字段
+		opts     replaceOptions
 	)
 
 	cmd := &cobra.Command{
@@ -28,11 +32,44 @@ Examples:
   # replace multiple fields
   gotool replace -p /tmp -o oldField1 -n newField1 -o oldField2 -n newField2
 
+  # replace with regular expression, the new value can reference the capture groups
+  gotool replace -p /tmp -o 'user_(\w+)' -n 'account_$1' --regexp
+
+  # replace whole words only, e.g. user is replaced but userName is not
+  gotool replace -p /tmp -o user -n account --whole-word
+
+  # replace all naming styles and plural forms, e.g. userOrder-->customerInvoice, USER_ORDERS-->CUSTOMER_INVOICES
+  gotool replace -p /tmp -o user_order -n customer_invoice --all-styles
+
+  # only replace identifiers, import paths and package names in go files, strings and comments are kept
+  gotool replace -p /tmp -o user -n account --go-aware
+
+  # rewrite the module paths of go.mod, import paths of go files and go_package of proto files
+  gotool replace -p /tmp --old-module=github.com/foo/template --new-module=github.com/bar/service
+
+  # ignore files and directories by glob, .gitignore and .gotoolignore in the source path are also applied
+  gotool replace -p /tmp -o oldField -n newField --ignore='**/*.pb.go' --ignore='docs/**' --ignore-dir=vendor
+
+  # binary files are copied verbatim by default, also replace the content of binary files
+  gotool replace -p /tmp -o oldField -n newField --replace-binary
+
+  # process files with 8 goroutines, failed files are reported together after all files are processed
+  gotool replace -p /tmp -o oldField -n newField --workers=8
+
+  # save the report of processed, skipped and renamed files and the replacements per field in json format
+  gotool replace -p /tmp -o oldField -n newField --report=report.json
+
+  # preview the replacements and the unified diff, nothing is written
+  gotool replace -p /tmp -o oldField -n newField --dry-run
+
+  # replace in the source directory, keep the original files as .bak files, roll back all changes if failed
+  gotool replace -p /tmp -o oldField -n newField --in-place --backup
+
 `,
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := runReplaceCommand(srcPath, oldValue, newValue)
+			err := runReplaceCommand(srcPath, oldValue, newValue, opts)
 			if err != nil {
 				return err
 			}
@@ -44,17 +81,56 @@ Examples:
 	_ = cmd.MarkFlagRequired("path")
 
 	cmd.Flags().StringArrayVarP(&oldValue, "old", "o", nil, "old value, one by one corresponding to the 'new' fields")
-	_ = cmd.MarkFlagRequired("old")
 	cmd.Flags().StringArrayVarP(&newValue, "new", "n", nil, "new value, one by one corresponding to the 'old' fields")
-	_ = cmd.MarkFlagRequired("new")
+	cmd.Flags().StringVarP(&opts.oldModule, "old-module", "", "", "old module path, rewrite the module paths of go.mod, import paths of go files and go_package of proto files to --new-module")
+	cmd.Flags().StringVarP(&opts.newModule, "new-module", "", "", "new module path, used with --old-module")
+	cmd.Flags().BoolVarP(&opts.isRegexp, "regexp", "", false, "the old values are regular expressions, the new values can reference the capture groups such as $1")
+	cmd.Flags().BoolVarP(&opts.isWholeWord, "whole-word", "", false, "replace whole words or identifiers only")
+	cmd.Flags().BoolVarP(&opts.isAllStyles, "all-styles", "", false, "replace all naming styles and plural forms, e.g. user_order also replaces userOrder, UserOrder, user-order, USER_ORDER, user order and userOrders")
+	cmd.Flags().BoolVarP(&opts.isGoAware, "go-aware", "", false, "only replace identifiers, import paths and package names in go files, strings and comments are kept, other files are replaced as text")
+	cmd.Flags().BoolVarP(&opts.replaceBinary, "replace-binary", "", false, "also replace the content of binary files, by default binary files such as images and archives are copied verbatim")
+	cmd.Flags().StringArrayVarP(&opts.ignoreFiles, "ignore", "", nil, "ignore files, support file names and glob patterns such as '*.pb.go', '**/*.pb.go' and 'docs/**'")
+	cmd.Flags().StringArrayVarP(&opts.ignoreDirs, "ignore-dir", "", nil, "ignore directories, support directory names, relative paths and glob patterns such as 'vendor', 'internal/model' and '**/testdata'")
+	cmd.Flags().IntVarP(&opts.workers, "workers", "", 0, "number of files processed concurrently, default is the number of CPUs")
+	cmd.Flags().StringVarP(&opts.reportFile, "report", "", "", "save the replacement report in json format to the file, including processed, skipped and renamed files and the number of replacements per field")
+	cmd.Flags().BoolVarP(&opts.dryRun, "dry-run", "", false, "print the number of replacements per file and the unified diff without writing files")
+	cmd.Flags().BoolVarP(&opts.inPlace, "in-place", "", false, "replace files in the source path instead of a new directory, roll back all changes if failed")
+	cmd.Flags().BoolVarP(&opts.backup, "backup", "", false, "keep the original files as .bak files, used with --in-place")
 
 	return cmd
 }
 
-func runReplaceCommand(srcPath string, oldValues []string, newValues []string) error {
+// 替换字段选项
+type replaceOptions struct {
+	isRegexp      bool     // 旧字段是否为正则表达式
+	isWholeWord   bool     // 是否只替换完整的单词或标识符
+	isAllStyles   bool     // 是否替换所有命名风格和复数形式
+	isGoAware     bool     // go文件是否只替换标识符、import路径和包名
+	oldModule     string   // 旧模块路径
+	newModule     string   // 新模块路径
+	ignoreFiles   []string // 忽略处理的文件，支持glob
+	ignoreDirs    []string // 忽略处理的目录，支持glob
+	replaceBinary bool     // 是否替换二进制文件的内容
+	dryRun        bool     // 只预览替换结果，不写入文件
+	inPlace       bool     // 在源目录替换
+	backup        bool     // 在源目录替换时保留.bak备份文件
+	workers       int      // 同时处理文件的goroutine数量
+	reportFile    string   // 替换报告的保存路径
+}
+
+func runReplaceCommand(srcPath string, oldValues []string, newValues []string, opts replaceOptions) error {
 	if len(oldValues) != len(newValues) {
 		return errors.New("len(old) must be equal to len(new)")
 	}
+	if len(oldValues) == 0 && opts.oldModule == "" {
+		return errors.New("old values or old module path must be specified")
+	}
+	if (opts.oldModule == "") != (opts.newModule == "") {
+		return errors.New("old module path and new module path must be specified together")
+	}
+	if opts.backup && !opts.inPlace {
+		return errors.New("--backup must be used with --in-place")
+	}
 
 	r, err := replacer.New(srcPath)
 	if err != nil {
@@ -62,25 +138,126 @@ func runReplaceCommand(srcPath string, oldValues []string, newValues []string) e
 	}
 
 	// 设置模板信息
-	templateIgnoreFiles := []string{} // 忽略处理的文件
+	templateIgnoreFiles := []string{}      // 忽略处理的文件
+	templateIgnoreDirs := []string{".git"} // 忽略处理的目录
 	var fields []replacer.Field
 	for i, old := range oldValues {
 		fields = append(fields, replacer.Field{
 			Old:             old,
 			New:             newValues[i],
 			IsCaseSensitive: false,
+			IsRegexp:        opts.isRegexp,
+			IsWholeWord:     opts.isWholeWord,
+			IsAllStyles:     opts.isAllStyles,
 		})
 	}
 
-	r.SetIgnoreFiles(templateIgnoreFiles...)
+	if err = r.LoadIgnoreFiles(); err != nil { // 读取.gitignore和.gotoolignore
+		return err
+	}
+	r.SetIgnoreFiles(append(templateIgnoreFiles, opts.ignoreFiles...)...)
+	r.SetIgnoreSubDirs(append(templateIgnoreDirs, opts.ignoreDirs...)...)
 	r.SetReplacementFields(fields)
-	if err = r.SetOutDir("", "replace"); err != nil {
+	r.SetGoAware(opts.isGoAware)
+	r.SetReplaceBinary(opts.replaceBinary)
+	if opts.oldModule != "" {
+		r.SetModulePath(opts.oldModule, opts.newModule)
+	}
+	r.SetWorkers(opts.workers)
+	if opts.dryRun {
+		changes, err := r.DryRun()
+		printChanges(changes) // 部分文件失败时仍然打印其他文件的结果
+		return finishReplace(r, opts.reportFile, err)
+	}
+
+	if opts.inPlace {
+		r.SetInPlace(opts.backup)
+	} else if err = r.SetOutDir("", "replace"); err != nil {
 		return err
 	}
+	r.SetProgress(func(done int, total int) {
+		fmt.Fprintf(os.Stderr, "\rreplacing files %d/%d", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	})
 	if err = r.SaveFiles(); err != nil {
-		return err
+		return finishReplace(r, opts.reportFile, err)
 	}
 
+	if err = finishReplace(r, opts.reportFile, nil); err != nil {
+		return err
+	}
 	fmt.Printf("replace successfully, output = %s\n\n", r.GetOutPath())
 	return nil
 }
+
+// 打印统计信息，保存替换报告，替换失败时也保存报告，用来查看每个文件失败的原因
+func finishReplace(r replacer.Replacer, reportFile string, replaceErr error) error {
+	rp := r.Report()
+	if rp == nil {
+		return replaceErr
+	}
+
+	printSummary(rp)
+	if reportFile != "" {
+		if err := rp.WriteJSON(reportFile); err != nil {
+			if replaceErr != nil {
+				return fmt.Errorf("%v\nsave report failed, %v", replaceErr, err)
+			}
+			return err
+		}
+		fmt.Printf("report = %s\n", reportFile)
+	}
+	return replaceErr
+}
+
+// 打印每个替换字段的替换次数和文件统计
+func printSummary(rp *replacer.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OLD\tNEW\tREPLACEMENTS")
+	for _, field := range rp.Fields {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", field.Old, field.New, field.Count)
+	}
+	_ = w.Flush()
+
+	reasons := map[string]int{}
+	for _, file := range rp.Files {
+		if file.Skipped != "" {
+			reasons[file.Skipped]++
+		}
+	}
+	var skipped []string
+	for _, reason := range []string{replacer.SkipIgnoredDir, replacer.SkipIgnoredFile, replacer.SkipIgnoreRule, replacer.SkipBinary, replacer.SkipTooLarge} {
+		if reasons[reason] > 0 {
+			skipped = append(skipped, fmt.Sprintf("%s %d", reason, reasons[reason]))
+		}
+	}
+	skippedInfo := ""
+	if len(skipped) > 0 {
+		skippedInfo = " (" + strings.Join(skipped, ", ") + ")"
+	}
+
+	fmt.Printf("\nprocessed %d files, changed %d, renamed %d, skipped %d%s, failed %d, replacements %d\n\n",
+		rp.Processed, rp.Changed, rp.Renamed, rp.Skipped, skippedInfo, rp.Failed, rp.Replacements)
+}
+
+// 打印每个文件的替换次数和diff
+func printChanges(changes []replacer.FileChange) {
+	total := 0
+	for _, change := range changes {
+		var counts []string
+		for _, rep := range change.Replacements {
+			counts = append(counts, fmt.Sprintf("'%s'->'%s' %d", rep.Old, rep.New, rep.Count))
+		}
+		fmt.Printf("%s: %d replacements %s\n", change.OldPath, change.Count(), strings.Join(counts, ", "))
+		total += change.Count()
+	}
+	fmt.Println()
+
+	for _, change := range changes {
+		fmt.Print(change.Diff)
+	}
+
+	fmt.Printf("\ndry run, %d files would be changed, %d replacements, nothing is written\n\n", len(changes), total)
+}
@@ -3,6 +3,7 @@ module github.com/zhufuyi/gotool
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/blastrain/vitess-sqlparser v0.0.0-20201030050434-a139afbb1aba
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/huandu/xstrings v1.3.2
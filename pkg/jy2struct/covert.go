@@ -3,22 +3,40 @@ package jy2struct
 import (
 	"bytes"
 	"errors"
+	"io"
 	"os"
 	"strings"
 )
 
+const (
+	// LangGo 生成go struct
+	LangGo = "go"
+	// LangTypeScript 生成typescript interface
+	LangTypeScript = "ts"
+)
+
 // Args  参数
 type Args struct {
-	Format    string // 文档格式，json或yaml
-	Data      string // json或yaml内容
-	InputFile string // 文件
-	Name      string // 结构体名称
-	SubStruct bool   // 子结构体是否分开
-	Tags      string // 字段tag，多个tag用逗号分隔
+	Format       string // 文档格式，json、ndjson、yaml、jsonschema、toml、xml、env或properties
+	Data         string // 文档内容
+	InputFile    string // 文件，format为json或ndjson时可以是目录，目录下所有json文件作为样本合并推断类型
+	Name         string // 结构体名称，默认GenerateName
+	PkgName      string // 包名，默认main
+	SubStruct    bool   // 子结构体是否分开
+	ParentPrefix bool   // 子结构体是否总是以父结构体名+字段名命名，默认以字段名命名，名称冲突时才加父结构体名
+	KeepOrder    bool   // 字段是否保持文档中key的顺序，默认按key排序，只支持json和yaml
+	Tags         string // 字段tag，多个tag用逗号分隔
+	Lang         string // 生成代码语言，go(默认)或ts
+	Detect       string // 从字符串值检测类型，可选time、duration、uuid，多个用逗号分隔
+	Omitempty    string // 添加omitempty的字段，optional(默认，部分样本缺少的字段)、all或none
+	UnknownType  string // null值等未知值的类型，interface{}(默认)、any或json.RawMessage
+	Loader       bool   // 是否生成加载配置文件的Load函数，包括默认值、环境变量覆盖和Validate方法，只支持yaml
+	EnvPrefix    string // 覆盖配置的环境变量前缀，默认APP，例如APP_SERVER_PORT
 
 	tags          []string
 	convertFloats bool
 	parser        Parser
+	opts          []Option
 }
 
 func (j *Args) checkValid() error {
@@ -26,29 +44,120 @@ func (j *Args) checkValid() error {
 	case "json":
 		j.parser = ParseJSON
 		j.convertFloats = true
+	case "ndjson":
+		j.parser = ParseNDJSON
+		j.convertFloats = true
 	case "yaml":
-		j.parser = ParseYaml
+		j.parser = ParseYamlWithComments
+	case "jsonschema":
+		j.parser = ParseJSON
+	case "toml":
+		j.parser = ParseToml
+	case "xml":
+		j.parser = ParseXML
+	case "env":
+		j.parser = ParseEnv
+	case "properties":
+		j.parser = ParseProperties
 	default:
-		return errors.New("format must be json or yaml")
+		return errors.New("format must be json, ndjson, yaml, jsonschema, toml, xml, env or properties")
 	}
 
-	j.tags = []string{j.Format}
+	formatTag := j.Format
+	if formatTag == "ndjson" || formatTag == "jsonschema" {
+		formatTag = "json"
+	}
+	j.tags = []string{formatTag}
 	tags := strings.Split(j.Tags, ",")
 	for _, tag := range tags {
-		if tag == j.Format || tag == "" {
+		if tag == formatTag || tag == "" {
 			continue
 		}
 		j.tags = append(j.tags, tag)
 	}
 
+	switch j.Lang {
+	case "", LangGo:
+		j.Lang = LangGo
+	case LangTypeScript:
+		if j.Format == "jsonschema" {
+			return errors.New("jsonschema format only supports go")
+		}
+	default:
+		return errors.New("lang must be go or ts")
+	}
+
+	if j.Format == "jsonschema" && strings.Trim(j.Detect, ", ") != "" {
+		return errors.New("jsonschema format does not support detect, types are detected by the format of the schema")
+	}
+	for _, d := range strings.Split(j.Detect, ",") {
+		switch strings.TrimSpace(d) {
+		case "":
+		case "time":
+			j.opts = append(j.opts, WithDetectTime())
+		case "duration":
+			j.opts = append(j.opts, WithDetectDuration())
+		case "uuid":
+			j.opts = append(j.opts, WithDetectUUID())
+		default:
+			return errors.New("detect must be time, duration or uuid")
+		}
+	}
+
+	switch j.Omitempty {
+	case "", "optional":
+	case "all":
+		j.opts = append(j.opts, WithOmitempty(OmitemptyAll))
+	case "none":
+		j.opts = append(j.opts, WithOmitempty(OmitemptyNone))
+	default:
+		return errors.New("omitempty must be optional, all or none")
+	}
+
+	switch j.UnknownType {
+	case "", UnknownInterface:
+	case UnknownAny, UnknownRawMessage:
+		j.opts = append(j.opts, WithUnknownType(j.UnknownType))
+	default:
+		return errors.New("unknown type must be interface{}, any or json.RawMessage")
+	}
+
+	if j.ParentPrefix {
+		j.opts = append(j.opts, WithParentPrefix())
+	}
+
+	if j.KeepOrder {
+		switch j.Format {
+		case "json":
+			j.parser = ParseJSONWithOrder
+		case "yaml":
+		default:
+			return errors.New("keep order only supports json and yaml format")
+		}
+		j.opts = append(j.opts, WithKeepOrder())
+	}
+
+	if j.Loader {
+		if j.Format != "yaml" || j.Lang != LangGo {
+			return errors.New("loader only supports yaml format and go")
+		}
+		if j.EnvPrefix == "" {
+			j.EnvPrefix = "APP"
+		}
+		j.opts = append(j.opts, WithLoader(j.EnvPrefix))
+	}
+
 	if j.Name == "" {
 		j.Name = "GenerateName"
 	}
+	if j.PkgName == "" {
+		j.PkgName = "main"
+	}
 
 	return nil
 }
 
-// Covert json或yaml转go struct
+// Covert json、yaml、toml、xml、env等文档转go struct或typescript interface，json schema转go struct
 func Covert(args *Args) (string, error) {
 	err := args.checkValid()
 	if err != nil {
@@ -58,6 +167,16 @@ func Covert(args *Args) (string, error) {
 	var data []byte
 	if args.Data != "" {
 		data = []byte(args.Data)
+	} else if isDir(args.InputFile) {
+		// 读取目录下所有json文件作为样本
+		if args.Format != "json" && args.Format != "ndjson" {
+			return "", errors.New("input directory only supports json or ndjson format")
+		}
+		s, err := readSampleDir(args.InputFile, args.parser)
+		if err != nil {
+			return "", err
+		}
+		args.parser = func(io.Reader) (interface{}, error) { return s, nil }
 	} else {
 		// 读取文件
 		data, err = os.ReadFile(args.InputFile)
@@ -68,10 +187,31 @@ func Covert(args *Args) (string, error) {
 
 	input := bytes.NewReader(data)
 
-	output, err := Generate(input, args.parser, args.Name, "main", args.tags, args.SubStruct, args.convertFloats)
+	if args.Lang == LangTypeScript {
+		output, err := GenerateTS(input, args.parser, args.Name)
+		if err != nil {
+			return "", err
+		}
+		return string(output), nil
+	}
+
+	if args.Format == "jsonschema" {
+		output, err := GenerateFromJSONSchema(input, args.Name, args.PkgName, args.tags, args.SubStruct, args.opts...)
+		if err != nil {
+			return "", err
+		}
+		return string(output), nil
+	}
+
+	output, err := Generate(input, args.parser, args.Name, args.PkgName, args.tags, args.SubStruct, args.convertFloats, args.opts...)
 	if err != nil {
 		return "", err
 	}
 
 	return string(output), nil
 }
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,6 +1,13 @@
 package jy2struct
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestCovert(t *testing.T) {
 	type args struct {
@@ -49,6 +56,24 @@ age: 10
 			}},
 			wantErr: false,
 		},
+		{
+			name: "json to typescript from file",
+			args: args{args: &Args{
+				InputFile: "test.json",
+				Format:    "json",
+				Lang:      LangTypeScript,
+			}},
+			wantErr: false,
+		},
+		{
+			name: "unknown lang",
+			args: args{args: &Args{
+				Data:   `{"name":"zhangsan"}`,
+				Format: "json",
+				Lang:   "java",
+			}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -61,3 +86,492 @@ age: 10
 		})
 	}
 }
+
+func TestGenerateTS(t *testing.T) {
+	data := `{"name":"zhangsan","age":22,"vip":true,"remark":null,"first-name":"san","tags":["a","b"],"company":{"name":"abc"},"orders":[{"id":1},{"id":2,"price":9.9}]}`
+	out, err := GenerateTS(strings.NewReader(data), ParseJSON, "User")
+	assert.NoError(t, err)
+	code := string(out)
+	assert.Contains(t, code, "export interface User {")
+	assert.Contains(t, code, "age: number;")
+	assert.Contains(t, code, "vip: boolean;")
+	assert.Contains(t, code, "remark?: unknown;")
+	assert.Contains(t, code, `"first-name": string;`)
+	assert.Contains(t, code, "tags: string[];")
+	assert.Contains(t, code, "company: Company;")
+	assert.Contains(t, code, "orders: Orders[];")
+	assert.Contains(t, code, "export interface Orders {\n  id: number;\n  price: number;\n}")
+	t.Log(code)
+}
+
+func TestGenerateTSNameCollision(t *testing.T) {
+	data := `{"a":{"data":{"x":1}},"b":{"data":{"y":"s"}}}`
+	out, err := GenerateTS(strings.NewReader(data), ParseJSON, "Root")
+	assert.NoError(t, err)
+	code := string(out)
+	assert.Contains(t, code, "export interface A {\n  data: Data;\n}")
+	assert.Contains(t, code, "export interface B {\n  data: BData;\n}")
+	assert.Contains(t, code, "export interface Data {\n  x: number;\n}")
+	assert.Contains(t, code, "export interface BData {\n  y: string;\n}")
+	assert.Equal(t, 1, strings.Count(code, "export interface Data "))
+
+	// the same names as the go sub structs
+	goOut, err := Generate(strings.NewReader(data), ParseJSON, "Root", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	assert.Contains(t, string(goOut), "type BData struct")
+	t.Log(code)
+}
+
+func TestGenerateSamples(t *testing.T) {
+	data := `{"id":1,"name":"zhangsan","score":90,"code":"a1","address":{"city":"sz"},"orders":[{"id":1}]}
+{"id":2,"name":"lisi","score":90.5,"code":100,"remark":null,"address":{"city":"gz","zip":"510000"},"orders":[{"id":2,"price":9.9}]}
+{"id":3,"score":80,"code":"c3","address":{"city":"bj"}}`
+	out, err := Generate(strings.NewReader(data), ParseNDJSON, "User", "main", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	code := string(out)
+	assert.Contains(t, code, "ID      int64       `json:\"id\"`")
+	assert.Contains(t, code, "Name    *string     `json:\"name,omitempty\"`")
+	assert.Contains(t, code, "Score   float64     `json:\"score\"`")
+	assert.Contains(t, code, "Code    string      `json:\"code\"`")
+	assert.Contains(t, code, "Remark  interface{} `json:\"remark,omitempty\"`")
+	assert.Contains(t, code, "Orders  []Orders    `json:\"orders,omitempty\"`")
+	assert.Contains(t, code, "Zip  *string `json:\"zip,omitempty\"`")
+	assert.Contains(t, code, "Price *float64 `json:\"price,omitempty\"`")
+	t.Log(code)
+
+	out, err = GenerateTS(strings.NewReader(data), ParseNDJSON, "User")
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "name?: string;")
+
+	_, err = Generate(strings.NewReader(`{"id":1}`+"\n"+`[1,2]`), ParseNDJSON, "User", "main", nil, true, true)
+	assert.Error(t, err)
+}
+
+func TestCovertSampleDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "1.json"), []byte(`{"id":1,"name":"zhangsan"}`), 0666))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "2.json"), []byte(`[{"id":2},{"id":3.5,"name":"lisi"}]`), 0666))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "readme.txt"), []byte(`not json`), 0666))
+
+	code, err := Covert(&Args{Format: "json", InputFile: dir, Name: "User"})
+	assert.NoError(t, err)
+	assert.Contains(t, code, "ID   float64 `json:\"id\"`")
+	assert.Contains(t, code, "Name *string `json:\"name,omitempty\"`")
+	t.Log(code)
+
+	_, err = Covert(&Args{Format: "yaml", InputFile: dir})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "json", InputFile: t.TempDir()})
+	assert.Error(t, err)
+}
+
+func TestGenerateFromJSONSchema(t *testing.T) {
+	schema := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "description": "a user",
+  "required": ["id", "name", "status", "created_at"],
+  "properties": {
+    "id": {"type": "string", "format": "uuid"},
+    "name": {"type": "string", "description": "user name"},
+    "age": {"type": "integer"},
+    "score": {"type": ["number", "null"]},
+    "status": {"type": "string", "enum": ["active", "in-progress", "DISABLED"]},
+    "level": {"enum": [1, 2, 3]},
+    "created_at": {"type": "string", "format": "date-time"},
+    "address": {"$ref": "#/$defs/address"},
+    "addresses": {"type": "array", "items": {"$ref": "#/$defs/address"}},
+    "parent": {"$ref": "#"},
+    "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+    "contact": {"oneOf": [
+      {"type": "object", "properties": {"email": {"type": "string"}}},
+      {"type": "object", "properties": {"phone": {"type": "string"}}}
+    ]},
+    "nickname": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+    "extra": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+    "vip": {"allOf": [{"$ref": "#/$defs/address"}, {"required": ["expire"], "properties": {"expire": {"type": "integer"}}}]}
+  },
+  "$defs": {
+    "address": {
+      "type": "object",
+      "required": ["city"],
+      "properties": {"city": {"type": "string"}, "zip": {"type": "string"}}
+    }
+  }
+}`
+	out, err := GenerateFromJSONSchema(strings.NewReader(schema), "User", "", []string{"json"}, true)
+	assert.NoError(t, err)
+	code := string(out)
+	t.Log(code)
+	assert.Contains(t, code, "// User a user\ntype User struct {")
+	assert.Regexp(t, `ID +uuid.UUID +`+"`json:\"id\"`", code)
+	assert.Regexp(t, "// user name\n\tName +string +`json:\"name\"`", code)
+	assert.Regexp(t, `Age +\*int64 +`+"`json:\"age,omitempty\"`", code)
+	assert.Regexp(t, `Score +\*float64 +`+"`json:\"score,omitempty\"`", code)
+	assert.Regexp(t, `Status +Status +`+"`json:\"status\"`", code)
+	assert.Regexp(t, `Level +\*Level +`+"`json:\"level,omitempty\"`", code)
+	assert.Regexp(t, `CreatedAt +time.Time +`+"`json:\"created_at\"`", code)
+	assert.Regexp(t, `Address +\*Address +`+"`json:\"address,omitempty\"`", code)
+	assert.Regexp(t, `Addresses +\[\]Address +`+"`json:\"addresses,omitempty\"`", code)
+	assert.Regexp(t, `Parent +\*User +`+"`json:\"parent,omitempty\"`", code)
+	assert.Regexp(t, `Labels +map\[string\]string +`+"`json:\"labels,omitempty\"`", code)
+	assert.Regexp(t, `Contact +\*Contact +`+"`json:\"contact,omitempty\"`", code)
+	assert.Regexp(t, `Nickname +\*string +`+"`json:\"nickname,omitempty\"`", code)
+	assert.Regexp(t, `Extra +interface{} +`+"`json:\"extra,omitempty\"`", code)
+	assert.Contains(t, code, "type Status string")
+	assert.Regexp(t, `StatusActive +Status = "active"`, code)
+	assert.Regexp(t, `StatusInProgress +Status = "in-progress"`, code)
+	assert.Regexp(t, `StatusDisabled +Status = "DISABLED"`, code)
+	assert.Regexp(t, `Level1 Level = 1`, code)
+	assert.Regexp(t, "City string +`json:\"city\"`", code)
+	assert.Regexp(t, "Email \\*string `json:\"email,omitempty\"`", code)
+	assert.Regexp(t, "Expire int64 +`json:\"expire\"`", code)
+
+	out, err = GenerateFromJSONSchema(strings.NewReader(`{"properties": {
+  "a": {"type": "object", "properties": {"data": {"type": "object", "properties": {"x": {"type": "integer"}}}}},
+  "b": {"type": "object", "properties": {"data": {"type": "object", "properties": {"y": {"type": "string"}}}}},
+  "c": {"type": "object", "properties": {"data": {"type": "object", "properties": {"x": {"type": "integer"}}}}}
+}}`), "User", "model", []string{"json"}, true)
+	assert.NoError(t, err)
+	code = string(out)
+	assert.Regexp(t, "type A struct {\n\tData \\*Data", code)
+	assert.Regexp(t, "type B struct {\n\tData \\*BData", code)
+	assert.Regexp(t, "C \\*A +`json:\"c,omitempty\"`", code) // the same struct
+	assert.Contains(t, code, "type BData struct {\n\tY *string")
+	assert.NotContains(t, code, "Data2")
+
+	_, err = GenerateFromJSONSchema(strings.NewReader(`{"properties": {"a": {"$ref": "other.json#/a"}}}`), "User", "", []string{"json"}, true)
+	assert.Error(t, err)
+
+	// only references to objects reserve the name
+	out, err = GenerateFromJSONSchema(strings.NewReader(`{"properties": {
+  "name": {"$ref": "#/$defs/data"},
+  "tags": {"$ref": "#/$defs/tags"},
+  "x": {"type": "object", "properties": {"data": {"type": "object", "properties": {"v": {"type": "integer"}}}}}
+}, "$defs": {"data": {"type": "string"}, "tags": {"type": "array", "items": {"type": "string"}}}}`), "User", "", []string{"json"}, true)
+	assert.NoError(t, err)
+	code = string(out)
+	assert.Regexp(t, "Name +\\*string", code)
+	assert.Regexp(t, "Tags +\\[\\]string", code)
+	assert.Contains(t, code, "type Data struct {")
+	assert.NotContains(t, code, "XData")
+
+	// options work the same as json and yaml
+	out, err = GenerateFromJSONSchema(strings.NewReader(`{"required": ["a"], "properties": {
+  "a": {"type": "object", "properties": {"x": {}}},
+  "b": {"$ref": "#/$defs/b"}
+}, "$defs": {"b": {"type": "object", "properties": {"y": {"type": "array"}}}}}`), "User", "", []string{"json"}, false,
+		WithOmitempty(OmitemptyNone), WithUnknownType(UnknownAny))
+	assert.NoError(t, err)
+	code = string(out)
+	assert.Regexp(t, "A +struct {\n\t\tX any `json:\"x\"`\n\t} `json:\"a\"`", code)
+	assert.Regexp(t, "B +\\*B +`json:\"b\"`", code)
+	assert.Regexp(t, "Y \\[\\]any `json:\"y\"`", code)
+	assert.NotContains(t, code, "type A struct")
+
+	out, err = GenerateFromJSONSchema(strings.NewReader(`{"required": ["a"], "properties": {"a": {"type": "string"}}}`),
+		"User", "", []string{"json"}, true, WithOmitempty(OmitemptyAll))
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "`json:\"a,omitempty\"`")
+
+	_, err = Covert(&Args{Format: "jsonschema", Data: schema, Lang: LangTypeScript})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "jsonschema", Data: schema, Detect: "time"})
+	assert.Error(t, err)
+	code, err = Covert(&Args{Format: "jsonschema", Data: schema, SubStruct: true, Omitempty: "none", UnknownType: UnknownRawMessage})
+	assert.NoError(t, err)
+	assert.Regexp(t, `Extra +json.RawMessage +`+"`json:\"extra\"`", code)
+}
+
+func TestCovertFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  *Args
+		wants []string
+	}{
+		{
+			name: "toml",
+			args: &Args{Format: "toml", SubStruct: true, Data: "title = \"a\"\ncreated = 1979-05-27T07:32:00Z\n[database]\nport = 3306\n[[servers]]\nip = \"10.0.0.1\"\n"},
+			wants: []string{
+				"Created  time.Time `toml:\"created\"`",
+				"Database Database  `toml:\"database\"`",
+				"Servers  []Servers `toml:\"servers\"`",
+				"Port int64 `toml:\"port\"`",
+			},
+		},
+		{
+			name: "xml",
+			args: &Args{Format: "xml", SubStruct: true, Data: `<server id="1"><name>a</name><host lang="en">x</host><host>y</host></server>`},
+			wants: []string{
+				"ID   int64  `xml:\"id,attr\"`",
+				"Host []Host `xml:\"host\"`",
+				"Text string `xml:\",chardata\"`",
+				"Lang string `xml:\"lang,attr\"`",
+			},
+		},
+		{
+			name: "env",
+			args: &Args{Format: "env", SubStruct: true, Data: "# comment\nDB_HOST=localhost\nexport DB_PORT=3306 # port\nDEBUG=true\nRATIO=0.5\nZIP=\"0123\"\n"},
+			wants: []string{
+				"DBHost string  `env:\"DB_HOST\"`",
+				"DBPort int64   `env:\"DB_PORT\"`",
+				"Debug  bool    `env:\"DEBUG\"`",
+				"Ratio  float64 `env:\"RATIO\"`",
+				"Zip    string  `env:\"ZIP\"`",
+			},
+		},
+		{
+			name: "properties",
+			args: &Args{Format: "properties", SubStruct: true, Data: "! comment\nserver.port=8080\nserver.host : localhost\napp.name=demo \\\n  app\n"},
+			wants: []string{
+				"App    App    `properties:\"app\"`",
+				"Host string `properties:\"host\"`",
+				"Port int64  `properties:\"port\"`",
+				"Name string `properties:\"name\"`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Covert(tt.args)
+			assert.NoError(t, err)
+			for _, want := range tt.wants {
+				assert.Contains(t, got, want)
+			}
+			t.Log(got)
+		})
+	}
+
+	_, err := Covert(&Args{Format: "env", Data: "invalid line"})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "properties", Data: "a=1\na.b=2"})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "xml", Data: "<a><b></a>"})
+	assert.Error(t, err)
+}
+
+func TestCovertPackage(t *testing.T) {
+	code, err := Covert(&Args{Format: "yaml", Data: "created_at: 2022-10-01T10:00:00Z\nname: demo\n", Name: "Config", PkgName: "config"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "package config\n\nimport (\n\t\"time\"\n)\n\ntype Config struct {"))
+	t.Log(code)
+
+	code, err = Covert(&Args{Format: "json", Data: `{"name":"zhangsan"}`})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "package main\n\ntype GenerateName struct {"))
+
+	out, err := Generate(strings.NewReader(`{"name":"zhangsan"}`), ParseJSON, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "package")
+}
+
+func TestGenerateDetect(t *testing.T) {
+	data := `{"id":9223372036854775808,"big":123456789012345678901234567890,"n":1,"f":1.0,"r":[1,2.5],
+"at":"2022-10-01T10:00:00+08:00","ttl":"1h30m","uid":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","mixed":["2022-10-01T10:00:00Z","abc"]}`
+
+	out, err := Generate(strings.NewReader(data), ParseJSON, "User", "model", []string{"json"}, true, true,
+		WithDetectTime(), WithDetectDuration(), WithDetectUUID())
+	assert.NoError(t, err)
+	code := string(out)
+	t.Log(code)
+	assert.Contains(t, code, "import (\n\t\"encoding/json\"\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)")
+	assert.Contains(t, code, "At    time.Time     `json:\"at\"`")
+	assert.Contains(t, code, "Big   json.Number   `json:\"big\"`")
+	assert.Contains(t, code, "F     float64       `json:\"f\"`")
+	assert.Contains(t, code, "ID    uint64        `json:\"id\"`")
+	assert.Contains(t, code, "Mixed []string      `json:\"mixed\"`")
+	assert.Contains(t, code, "N     int64         `json:\"n\"`")
+	assert.Contains(t, code, "R     []float64     `json:\"r\"`")
+	assert.Contains(t, code, "TTL   time.Duration `json:\"ttl\"`")
+	assert.Contains(t, code, "UID   uuid.UUID     `json:\"uid\"`")
+
+	// detection is opt-in
+	out, err = Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "At    string      `json:\"at\"`")
+	assert.Contains(t, string(out), "UID   string      `json:\"uid\"`")
+
+	_, err = Covert(&Args{Format: "json", Data: data, Detect: "time,email"})
+	assert.Error(t, err)
+}
+
+func TestGenerateSubStructNames(t *testing.T) {
+	data := `{
+  "data": {"data": {"data": {"value": 1}}, "items": [{"id": 1, "data": {"name": "x"}}]},
+  "billing_address": {"city": "a"},
+  "shipping_address": {"city": "b"},
+  "list": [[{"k": "v"}]],
+  "meta": {"data": {"data": {"value": 2}}}
+}`
+	out, err := Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	code := string(out)
+	t.Log(code)
+
+	// different structs with the same key are named after parent+key
+	assert.Contains(t, code, "type Data struct {\n\tData  DataData `json:\"data\"`\n\tItems []Items  `json:\"items\"`\n}")
+	assert.Contains(t, code, "type DataData struct {\n\tData DataDataData `json:\"data\"`\n}")
+	assert.Contains(t, code, "type DataDataData struct {\n\tValue int64 `json:\"value\"`\n}")
+	assert.Contains(t, code, "type ItemsData struct {\n\tName string `json:\"name\"`\n}")
+	assert.Contains(t, code, "type MetaData struct {\n\tData MetaDataData `json:\"data\"`\n}")
+	// the same struct with different keys are different types
+	assert.Contains(t, code, "type BillingAddress struct {")
+	assert.Contains(t, code, "type ShippingAddress struct {")
+	assert.Contains(t, code, "List            [][]List        `json:\"list\"`")
+
+	// the output is deterministic
+	for i := 0; i < 10; i++ {
+		out2, err := Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true)
+		assert.NoError(t, err)
+		assert.Equal(t, code, string(out2))
+	}
+
+	// always named after parent+key
+	out, err = Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true, WithParentPrefix())
+	assert.NoError(t, err)
+	code = string(out)
+	t.Log(code)
+	assert.Contains(t, code, "Data            UserData            `json:\"data\"`")
+	assert.Contains(t, code, "type UserDataItems struct {\n\tData UserDataItemsData `json:\"data\"`")
+	assert.Contains(t, code, "type UserMetaDataData struct {")
+
+	// the same struct with the same key is generated once, numeric suffix when parent+key is taken
+	data = `{"a": {"x": {"n": 1}}, "b": {"x": {"n": 2}}, "ax": {"s": "1"}, "c": {"ax": {"t": true}}}`
+	out, err = Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	code = string(out)
+	t.Log(code)
+	assert.Equal(t, 1, strings.Count(code, "type X struct"))
+	assert.Contains(t, code, "type Ax struct {\n\tS string `json:\"s\"`\n}")
+	assert.Contains(t, code, "type CAx struct {\n\tT bool `json:\"t\"`\n}")
+
+	data = `{"b": {"p": 1}, "a_b": {"q": 1}, "a": {"b": {"r": 1}}}`
+	out, err = Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	code = string(out)
+	t.Log(code)
+	assert.Contains(t, code, "type A struct {\n\tB AB2 `json:\"b\"`\n}")
+}
+
+func TestGenerateNullable(t *testing.T) {
+	data := `{"list":[{"a":null,"b":1,"c":{"x":1}},{"a":2,"b":null,"c":null}],"n":null,"m":[1,"a"]}`
+	out, err := Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json", "yaml"}, true, true)
+	assert.NoError(t, err)
+	code := string(out)
+	t.Log(code)
+	assert.Contains(t, code, "A *int64 `json:\"a\" yaml:\"a\"`")
+	assert.Contains(t, code, "C *C     `json:\"c\" yaml:\"c\"`")
+	assert.Contains(t, code, "M    []interface{} `json:\"m\" yaml:\"m\"`")
+	assert.Contains(t, code, "N    interface{}   `json:\"n\" yaml:\"n\"`")
+
+	out, err = Generate(strings.NewReader(data), ParseJSON, "User", "", []string{"json"}, true, true,
+		WithUnknownType(UnknownRawMessage), WithOmitempty(OmitemptyAll))
+	assert.NoError(t, err)
+	code = string(out)
+	t.Log(code)
+	assert.Contains(t, code, "M    []json.RawMessage `json:\"m,omitempty\"`")
+	assert.Contains(t, code, "N    json.RawMessage   `json:\"n,omitempty\"`")
+	assert.Contains(t, code, "A *int64 `json:\"a,omitempty\"`")
+
+	ndjson := "{\"a\":1,\"b\":null}\n{\"a\":null,\"b\":null}\n{\"c\":\"x\"}"
+	out, err = Generate(strings.NewReader(ndjson), ParseNDJSON, "User", "", []string{"json"}, true, true,
+		WithUnknownType(UnknownAny), WithOmitempty(OmitemptyNone))
+	assert.NoError(t, err)
+	code = string(out)
+	t.Log(code)
+	assert.Contains(t, code, "A *int64  `json:\"a\"`")
+	assert.Contains(t, code, "B any     `json:\"b\"`")
+	assert.Contains(t, code, "C *string `json:\"c\"`")
+
+	out, err = GenerateTS(strings.NewReader(ndjson), ParseNDJSON, "User")
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "a?: number | null;")
+
+	_, err = Covert(&Args{Format: "json", Data: data, Omitempty: "some"})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "json", Data: data, UnknownType: "object"})
+	assert.Error(t, err)
+}
+
+func TestGenerateLoader(t *testing.T) {
+	data := `
+name: demo
+debug: true
+server:
+  host: localhost
+  port: 8080
+  timeout: 1h30m
+db:
+  maxConns: 10
+hosts: [a, b]
+`
+	out, err := Covert(&Args{Format: "yaml", Data: data, Name: "Config", PkgName: "config", Detect: "duration", Loader: true})
+	assert.NoError(t, err)
+	t.Log(out)
+	assert.Contains(t, out, "\"gopkg.in/yaml.v3\"")
+	assert.Contains(t, out, "func Load(path string) (*Config, error) {")
+	assert.Contains(t, out, "c.Server.Port = 8080")
+	assert.Contains(t, out, "c.Server.Timeout = 90 * time.Minute")
+	assert.Contains(t, out, `os.LookupEnv("APP_DB_MAX_CONNS")`)
+	assert.Contains(t, out, "return errors.New(\"server.host is required\")")
+	assert.NotContains(t, out, "c.Hosts")
+
+	out, err = Covert(&Args{Format: "yaml", Data: data, Loader: true, EnvPrefix: "MY"})
+	assert.NoError(t, err)
+	assert.Contains(t, out, `os.LookupEnv("MY_SERVER_HOST")`)
+
+	_, err = Covert(&Args{Format: "json", Data: `{"a":1}`, Loader: true})
+	assert.Error(t, err)
+	_, err = Covert(&Args{Format: "yaml", Data: "- a: 1", Loader: true})
+	assert.Error(t, err)
+}
+
+func TestGenerateYamlComments(t *testing.T) {
+	data := `# service config
+
+# app name
+name: demo # inline name
+server: # server config
+  # listen port
+  port: 8080
+  host: localhost
+defaults: &default
+  retry: 3
+job:
+  <<: *default
+  name: x
+`
+	out, err := Covert(&Args{Format: "yaml", Data: data, Name: "Config", SubStruct: true, KeepOrder: true})
+	assert.NoError(t, err)
+	t.Log(out)
+	assert.Contains(t, out, "// service config\ntype Config struct {\n\t// app name\n\tName     string   `yaml:\"name\"`   // inline name\n")
+	assert.Contains(t, out, "\tServer   Server   `yaml:\"server\"` // server config\n\tDefaults Defaults")
+	assert.Contains(t, out, "\t// listen port\n\tPort int    `yaml:\"port\"`\n\tHost string `yaml:\"host\"`\n}")
+	assert.Contains(t, out, "\tRetry int    `yaml:\"retry\"`\n\tName  string `yaml:\"name\"`")
+
+	out, err = Covert(&Args{Format: "yaml", Data: data, Lang: LangTypeScript})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "port: number;")
+}
+
+func TestGenerateKeepOrder(t *testing.T) {
+	data := `{"name":"foo","id":1,"address":{"zip":"100","city":"x"},"tags":[{"b":1,"a":2},{"c":3,"a":4}]}`
+	out, err := Generate(strings.NewReader(data), ParseJSONWithOrder, "User", "", []string{"json"}, true, true, WithKeepOrder())
+	assert.NoError(t, err)
+	code := string(out)
+	t.Log(code)
+	assert.Contains(t, code, "Name    string  `json:\"name\"`\n\tID      int64   `json:\"id\"`\n\tAddress Address `json:\"address\"`\n\tTags    []Tags  `json:\"tags\"`")
+	assert.Contains(t, code, "Zip  string `json:\"zip\"`\n\tCity string `json:\"city\"`")
+	assert.Contains(t, code, "B int64 `json:\"b\"`\n\tA int64 `json:\"a\"`\n\tC int64 `json:\"c\"`")
+
+	// sorted by default
+	out, err = Generate(strings.NewReader(data), ParseJSONWithOrder, "User", "", []string{"json"}, true, true)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "Address Address `json:\"address\"`\n\tID      int64   `json:\"id\"`")
+
+	out2, err := Covert(&Args{Format: "json", Data: data, KeepOrder: true})
+	assert.NoError(t, err)
+	assert.Contains(t, out2, "Zip  string `json:\"zip\"`\n\t\tCity string `json:\"city\"`")
+	_, err = Covert(&Args{Format: "toml", Data: "a = 1", KeepOrder: true})
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package jy2struct
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document a parsed document with the key order and comments of the source, they are looked up by the path of
+// the object or field, the elements of an array share the path of the array
+type document struct {
+	value    interface{}
+	keys     map[string][]string     // object path -> keys in document order
+	comments map[string]fieldComment // field path -> comments, the path of the root is ""
+}
+
+type fieldComment struct {
+	head string // comment lines above the field
+	line string // comment at the end of the field line
+}
+
+// ParseJSONWithOrder parse json to struct, the key order of the document is recorded for WithKeepOrder,
+// numbers are decoded as json.Number
+func ParseJSONWithOrder(input io.Reader) (interface{}, error) {
+	decoder := json.NewDecoder(input)
+	decoder.UseNumber()
+	doc := &document{
+		keys:     make(map[string][]string),
+		comments: make(map[string]fieldComment),
+	}
+	value, err := doc.decodeJSONValue(decoder, "")
+	if err != nil {
+		return nil, err
+	}
+	doc.value = value
+	return doc, nil
+}
+
+// decode the json value by tokens, the keys of objects are recorded in order
+func (d *document) decodeJSONValue(decoder *json.Decoder, path string) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		for decoder.More() {
+			token, err = decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := token.(string) // keys of json objects are always strings
+			d.addKey(path, key)
+			obj[key], err = d.decodeJSONValue(decoder, subPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = decoder.Token() // '}'
+		return obj, err
+	case json.Delim('['):
+		list := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := d.decodeJSONValue(decoder, path)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = decoder.Token() // ']'
+		return list, err
+	}
+	return token, nil
+}
+
+// ParseYamlWithComments parse yaml to struct, the key order of the document is recorded for WithKeepOrder,
+// head and line comments of the keys are added to the struct fields
+func ParseYamlWithComments(input io.Reader) (interface{}, error) {
+	b, err := readFile(input)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = node.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	doc := &document{
+		value:    value,
+		keys:     make(map[string][]string),
+		comments: make(map[string]fieldComment),
+	}
+	doc.addComment("", node.HeadComment, "")
+	doc.walkYamlNode(&node, "")
+	return doc, nil
+}
+
+func (d *document) walkYamlNode(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, n := range node.Content {
+			d.walkYamlNode(n, path)
+		}
+	case yaml.AliasNode:
+		d.walkYamlNode(node.Alias, path)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				d.walkYamlNode(value, path) // keys of the merged mapping, e.g. <<: *default
+				continue
+			}
+			fieldPath := subPath(path, key.Value)
+			d.addKey(path, key.Value)
+			line := key.LineComment
+			if line == "" {
+				line = value.LineComment
+			}
+			d.addComment(fieldPath, key.HeadComment, line)
+			d.walkYamlNode(value, fieldPath)
+		}
+	}
+}
+
+func (d *document) addKey(path string, key string) {
+	for _, k := range d.keys[path] {
+		if k == key {
+			return
+		}
+	}
+	d.keys[path] = append(d.keys[path], key)
+}
+
+// the first comments of the field are kept, e.g. comments in the first element of an array
+func (d *document) addComment(path string, head string, line string) {
+	if head == "" && line == "" {
+		return
+	}
+	if _, ok := d.comments[path]; !ok {
+		d.comments[path] = fieldComment{head: head, line: line}
+	}
+}
+
+// the keys of the object in document order if KeepOrder is set, keys not found in the document are appended
+// in sorted order
+func (g *generator) objectKeys(obj map[string]interface{}, path string) []string {
+	if g.doc == nil || !g.KeepOrder {
+		return sortedKeys(obj)
+	}
+
+	keys := make([]string, 0, len(obj))
+	seen := make(map[string]bool, len(obj))
+	for _, key := range g.doc.keys[path] {
+		if _, ok := obj[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	for _, key := range sortedKeys(obj) {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (g *generator) comment(path string) fieldComment {
+	if g.doc == nil {
+		return fieldComment{}
+	}
+	return g.doc.comments[path]
+}
+
+// convert yaml comments to go comments, e.g. "# port" is "// port"
+func goComment(comment string) string {
+	lines := strings.Split(strings.TrimSpace(comment), "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		line = strings.TrimPrefix(line, " ")
+		if line == "" {
+			lines[i] = "//"
+		} else {
+			lines[i] = "// " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,253 @@
+package jy2struct
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	xmlAttrPrefix = "@"     // key prefix of xml attributes
+	xmlTextKey    = "#text" // key of xml text content in an element with attributes or children
+)
+
+// ParseToml parse toml to struct
+func ParseToml(input io.Reader) (interface{}, error) {
+	var result map[string]interface{}
+	if _, err := toml.NewDecoder(input).Decode(&result); err != nil {
+		return nil, err
+	}
+	return normalizeValue(result), nil
+}
+
+// array of tables are decoded as []map[string]interface{}, convert them to []interface{}
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeValue(e)
+		}
+		return v
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			list = append(list, normalizeValue(e))
+		}
+		return list
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeValue(e)
+		}
+		return v
+	}
+	return value
+}
+
+// ParseXML parse xml to struct, attributes are keys with prefix @, repeated elements are arrays,
+// the text of elements with attributes or children is key #text
+func ParseXML(input io.Reader) (interface{}, error) {
+	decoder := xml.NewDecoder(input)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no xml element found")
+			}
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok := value.(map[string]interface{}); ok {
+				return obj, nil
+			}
+			return map[string]interface{}{start.Name.Local: value}, nil
+		}
+	}
+}
+
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	obj := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		obj[xmlAttrPrefix+attr.Name.Local] = inferScalar(attr.Value)
+	}
+
+	text := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			key := t.Name.Local
+			if v, ok := obj[key]; ok {
+				if list, ok := v.([]interface{}); ok {
+					obj[key] = append(list, value)
+				} else {
+					obj[key] = []interface{}{v, value}
+				}
+			} else {
+				obj[key] = value
+			}
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			for key, v := range obj {
+				if list, ok := v.([]interface{}); ok {
+					obj[key] = wrapXMLText(list)
+				}
+			}
+			text = strings.TrimSpace(text)
+			if len(obj) == 0 {
+				return inferScalar(text), nil
+			}
+			if text != "" {
+				obj[xmlTextKey] = inferScalar(text)
+			}
+			return obj, nil
+		}
+	}
+}
+
+// if some of the repeated elements have attributes or children, the text of other elements is wrapped as #text,
+// so all elements are the same struct
+func wrapXMLText(list []interface{}) []interface{} {
+	hasObject := false
+	for _, v := range list {
+		if _, ok := v.(map[string]interface{}); ok {
+			hasObject = true
+			break
+		}
+	}
+	if !hasObject {
+		return list
+	}
+	for i, v := range list {
+		if _, ok := v.(map[string]interface{}); !ok {
+			list[i] = map[string]interface{}{xmlTextKey: v}
+		}
+	}
+	return list
+}
+
+// ParseEnv parse .env file to struct, keys are kept as they are, e.g. DB_HOST=localhost
+func ParseEnv(input io.Reader) (interface{}, error) {
+	result := make(map[string]interface{})
+	scanner := bufio.NewScanner(input)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: invalid env %q", lineNum, line)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			result[key] = value[1 : len(value)-1] // quoted values are always strings
+			continue
+		}
+		if j := strings.Index(value, " #"); j >= 0 {
+			value = strings.TrimSpace(value[:j])
+		}
+		result[key] = inferScalar(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParseProperties parse .properties file to struct, keys separated by dots are nested,
+// e.g. server.port=8080 is {"server": {"port": 8080}}
+func ParseProperties(input io.Reader) (interface{}, error) {
+	result := make(map[string]interface{})
+	scanner := bufio.NewScanner(input)
+	logical := ""
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if logical == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		// a line ending with backslash continues on the next line
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			logical += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		line, logical = logical+line, ""
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			i = len(line)
+		}
+		key, value := strings.TrimSpace(line[:i]), ""
+		if i < len(line) {
+			value = strings.TrimSpace(line[i+1:])
+		}
+		if err := setProperty(result, strings.Split(key, "."), inferScalar(value)); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func setProperty(obj map[string]interface{}, keys []string, value interface{}) error {
+	for i, key := range keys[:len(keys)-1] {
+		sub, ok := obj[key]
+		if !ok {
+			sub = make(map[string]interface{})
+			obj[key] = sub
+		}
+		subObj, ok := sub.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("property %s is both a value and an object", strings.Join(keys[:i+1], "."))
+		}
+		obj = subObj
+	}
+
+	key := keys[len(keys)-1]
+	if _, ok := obj[key].(map[string]interface{}); ok {
+		return fmt.Errorf("property %s is both a value and an object", strings.Join(keys, "."))
+	}
+	obj[key] = value
+	return nil
+}
+
+// infer the type of string value, e.g. true is bool, 8080 is int64, 0.5 is float64
+func inferScalar(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil && (s == "0" || !strings.HasPrefix(strings.TrimLeft(s, "+-"), "0")) {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.Contains(s, ".") {
+		return f
+	}
+	return s
+}
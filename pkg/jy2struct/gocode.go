@@ -0,0 +1,85 @@
+package jy2struct
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// import paths of the packages used by the generated code
+var typePackages = map[string]string{
+	"time":    "time",
+	"json":    "encoding/json",
+	"uuid":    "github.com/google/uuid",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"os":      "os",
+	"strconv": "strconv",
+	"yaml":    "gopkg.in/yaml.v3",
+}
+
+// format the generated code, if pkgName is not empty, add the package clause and imports of the used packages
+func formatSource(pkgName string, src string) ([]byte, error) {
+	if pkgName != "" {
+		header := fmt.Sprintf("package %s\n", pkgName)
+		if importPaths := detectImports(src); len(importPaths) > 0 {
+			header += "\nimport (\n"
+			for i, path := range importPaths {
+				if i > 0 && isStdPackage(importPaths[i-1]) && !isStdPackage(path) {
+					header += "\n"
+				}
+				header += fmt.Sprintf("\t%q\n", path)
+			}
+			header += ")\n"
+		}
+		src = header + src
+	}
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		err = fmt.Errorf("error formatting: %s, was formatting\n%s", err, src)
+	}
+	return formatted, err
+}
+
+// find the packages used by the code, e.g. time.Time uses time
+func detectImports(src string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+	if err != nil {
+		return nil // the error is reported when formatting
+	}
+
+	found := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := typePackages[ident.Name]; ok {
+					found[path] = true
+				}
+			}
+		}
+		return true
+	})
+
+	importPaths := make([]string, 0, len(found))
+	for path := range found {
+		importPaths = append(importPaths, path)
+	}
+	// standard library first
+	sort.Slice(importPaths, func(i, j int) bool {
+		iStd, jStd := isStdPackage(importPaths[i]), isStdPackage(importPaths[j])
+		if iStd != jStd {
+			return iStd
+		}
+		return importPaths[i] < importPaths[j]
+	})
+	return importPaths
+}
+
+func isStdPackage(path string) bool {
+	return !strings.Contains(path, ".")
+}
@@ -0,0 +1,105 @@
+package jy2struct
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kinds of string value
+const (
+	stringPlain = iota
+	stringTime
+	stringDuration
+	stringUUID
+)
+
+var (
+	durationRegexp = regexp.MustCompile(`^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// kind of the string value, regardless of whether detection is enabled
+func stringKind(s string) int {
+	switch {
+	case uuidRegexp.MatchString(s):
+		return stringUUID
+	case durationRegexp.MatchString(s):
+		return stringDuration
+	case len(s) >= len("2006-01-02T15:04:05Z") && s[4] == '-' && s[10] == 'T':
+		if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return stringTime
+		}
+	}
+	return stringPlain
+}
+
+// kinds of string value of the JSON Schema formats
+var formatKinds = map[string]int{
+	"date-time": stringTime,
+	"uuid":      stringUUID,
+}
+
+// type of the string value with the enabled detections
+func (g *generator) stringType(s string) string {
+	kind := stringKind(s)
+	switch kind {
+	case stringTime:
+		if !g.DetectTime {
+			kind = stringPlain
+		}
+	case stringDuration:
+		if !g.DetectDuration {
+			kind = stringPlain
+		}
+	case stringUUID:
+		if !g.DetectUUID {
+			kind = stringPlain
+		}
+	}
+	return kindType(kind)
+}
+
+// go type of the kind of string value
+func kindType(kind int) string {
+	switch kind {
+	case stringTime:
+		return "time.Time"
+	case stringDuration:
+		return "time.Duration"
+	case stringUUID:
+		return "uuid.UUID"
+	}
+	return "string"
+}
+
+// type of the number decoded with UseNumber, integers out of the range of int64 and uint64 are json.Number,
+// which is a string type that keeps all digits
+func numberType(n json.Number) string {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return "int64"
+		}
+		if _, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return "uint64"
+		}
+		return "json.Number"
+	}
+	return "float64"
+}
+
+// the wider number type wins when merging numbers, int64 < uint64 < json.Number < float64
+func numberRank(n json.Number) int {
+	switch numberType(n) {
+	case "int64":
+		return 0
+	case "uint64":
+		return 1
+	case "json.Number":
+		return 2
+	}
+	return 3
+}
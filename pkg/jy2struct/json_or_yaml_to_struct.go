@@ -3,14 +3,14 @@ package jy2struct
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"go/format"
 	"io"
 	"math"
 	"reflect"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"gopkg.in/yaml.v3"
@@ -19,6 +19,16 @@ import (
 // ForceFloats whether to force a change to float
 var ForceFloats bool
 
+// tags that support the omitempty option
+var omitemptyTags = map[string]bool{
+	"json":         true,
+	"yaml":         true,
+	"bson":         true,
+	"xml":          true,
+	"toml":         true,
+	"mapstructure": true,
+}
+
 // commonInitialisms is a set of common initialisms.
 // Only add entries that are highly unlikely to be non-initialisms.
 // For instance, "ID" is fine (Freudian code is rare), but "AND" is not.
@@ -74,10 +84,12 @@ var intToWordMap = []string{
 // Parser parser function
 type Parser func(io.Reader) (interface{}, error)
 
-// ParseJSON parse json to struct
+// ParseJSON parse json to struct, numbers are decoded as json.Number to keep the precision of large integers
 func ParseJSON(input io.Reader) (interface{}, error) {
 	var result interface{}
-	if err := json.NewDecoder(input).Decode(&result); err != nil {
+	decoder := json.NewDecoder(input)
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -105,11 +117,30 @@ func readFile(input io.Reader) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// Generate a struct definition given a JSON string representation of an object and a name structName.
-func Generate(input io.Reader, parser Parser, structName, pkgName string, tags []string, subStruct bool, convertFloats bool) ([]byte, error) {
-	var subStructMap map[string]string = nil
-	if subStruct {
-		subStructMap = make(map[string]string)
+// generator go struct generator
+type generator struct {
+	tags          []string
+	subStruct     bool // whether sub structs are separated
+	convertFloats bool
+	options
+
+	structNames  map[string]string // path of the nested object -> sub struct name
+	structBodies map[string]string // struct name -> inline struct body, the name is reused only by the same struct
+	subStructs   map[string]string // sub struct name -> struct body
+	doc          *document         // key order and comments of the source, nil if not kept
+}
+
+// Generate a struct definition given a JSON string representation of an object and a name structName,
+// if pkgName is not empty, the package clause and imports are added.
+func Generate(input io.Reader, parser Parser, structName, pkgName string, tags []string, subStruct bool, convertFloats bool, opts ...Option) ([]byte, error) {
+	g := &generator{
+		tags:          tags,
+		subStruct:     subStruct,
+		convertFloats: convertFloats,
+		options:       parseOption(opts),
+		structNames:   make(map[string]string),
+		structBodies:  make(map[string]string),
+		subStructs:    make(map[string]string),
 	}
 
 	var result map[string]interface{}
@@ -118,48 +149,45 @@ func Generate(input io.Reader, parser Parser, structName, pkgName string, tags [
 	if err != nil {
 		return nil, err
 	}
+	if doc, ok := iresult.(*document); ok {
+		g.doc = doc
+		iresult = doc.value
+	}
 
 	switch iresult := iresult.(type) {
+	case samples:
+		result, err = mergeSamples(iresult)
+		if err != nil {
+			return nil, err
+		}
 	case map[interface{}]interface{}:
 		result = convertKeysToStrings(iresult)
 	case map[string]interface{}:
 		result = iresult
 	case []interface{}:
-		//src := fmt.Sprintf("package %s\n\ntype %s %s\n", pkgName, structName, typeForValue(iresult, structName, tags, subStructMap, convertFloats))
-		src := fmt.Sprintf("\ntype %s %s\n", structName, typeForValue(iresult, structName, tags, subStructMap, convertFloats))
-		// 补上子结构体
-		for k, v := range subStructMap {
-			src += fmt.Sprintf("\n\ntype %s %s\n\n", v, k)
-		}
-		var formatted []byte
-		formatted, err = format.Source([]byte(src))
-		if err != nil {
-			err = fmt.Errorf("error formatting: %s, was formatting\n%s", err, src)
+		if g.Loader {
+			return nil, errors.New("loader requires the root of the config to be an object")
 		}
-		return formatted, err
+		g.nameSubStructs(iresult, structName)
+		src := fmt.Sprintf("\ntype %s %s\n", structName, g.typeForValue(iresult, ""))
+		// 补上子结构体
+		src += g.subStructsSource()
+		return formatSource(pkgName, src)
 	default:
 		return nil, fmt.Errorf("unexpected type: %T", iresult)
 	}
 
-	//src := fmt.Sprintf("package %s\ntype %s %s}", pkgName, structName, generateTypes(result, structName, tags, 0, subStructMap, convertFloats))
-	src := fmt.Sprintf("\ntype %s %s}", structName, generateTypes(result, structName, tags, 0, subStructMap, convertFloats))
-
-	keys := make([]string, 0, len(subStructMap))
-	for key := range subStructMap {
-		keys = append(keys, key)
+	g.nameSubStructs(result, structName)
+	src := fmt.Sprintf("\ntype %s %s}", structName, g.generateTypes(result, ""))
+	if c := g.comment(""); c.head != "" {
+		src = "\n" + goComment(c.head) + src
 	}
-
-	sort.Strings(keys)
-
-	for _, k := range keys {
-		src = fmt.Sprintf("%v\n\ntype %v %v", src, subStructMap[k], k)
+	src += g.subStructsSource()
+	if g.Loader {
+		src += g.loaderSource(result, structName)
 	}
 
-	formatted, err := format.Source([]byte(src))
-	if err != nil {
-		err = fmt.Errorf("error formatting: %s, was formatting\n%s", err, src)
-	}
-	return formatted, err
+	return formatSource(pkgName, src)
 }
 
 func convertKeysToStrings(obj map[interface{}]interface{}) map[string]interface{} {
@@ -172,95 +200,72 @@ func convertKeysToStrings(obj map[interface{}]interface{}) map[string]interface{
 	return res
 }
 
-// Generate go struct entries for a map[string]interface{} structure
-func generateTypes(obj map[string]interface{}, structName string, tags []string, depth int, subStructMap map[string]string, convertFloats bool) string {
+// Generate go struct entries for a map[string]interface{} structure, path is the path of the object
+func (g *generator) generateTypes(obj map[string]interface{}, path string) string {
 	structure := "struct {"
+	lastLineComment := false
 
-	keys := make([]string, 0, len(obj))
-	for key := range obj {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-
-	for _, key := range keys {
-		value := obj[key]
-		valueType := typeForValue(value, structName, tags, subStructMap, convertFloats)
-
-		//value = mergeElements(value)
-
-		//If a nested value, recurse
-		switch value := value.(type) {
-		case []interface{}:
-			if len(value) > 0 {
-				sub := ""
-				if v, ok := value[0].(map[interface{}]interface{}); ok {
-					sub = generateTypes(convertKeysToStrings(v), structName, tags, depth+1, subStructMap, convertFloats) + "}"
-				} else if v, ok := value[0].(map[string]interface{}); ok {
-					sub = generateTypes(v, structName, tags, depth+1, subStructMap, convertFloats) + "}"
-				}
-
-				if sub != "" {
-					subName := sub
-
-					if subStructMap != nil {
-						if val, ok := subStructMap[sub]; ok {
-							subName = val
-						} else {
-							//subName = fmt.Sprintf("%v_sub%v", structName, len(subStructMap)+1)
-							subName = FmtFieldName(key) // 使用字段名字
-							subStructMap[sub] = subName
-						}
-					}
-
-					valueType = "[]" + subName
-				}
-			}
-		case map[interface{}]interface{}:
-			sub := generateTypes(convertKeysToStrings(value), structName, tags, depth+1, subStructMap, convertFloats) + "}"
-			subName := sub
-
-			if subStructMap != nil {
-				if val, ok := subStructMap[sub]; ok {
-					subName = val
-				} else {
-					//subName = fmt.Sprintf("%v_sub%v", structName, len(subStructMap)+1)
-					subName = FmtFieldName(key) // 使用字段名字
-					subStructMap[sub] = subName
-				}
-			}
-			valueType = subName
-		case map[string]interface{}:
-			sub := generateTypes(value, structName, tags, depth+1, subStructMap, convertFloats) + "}"
-			subName := sub
-
-			if subStructMap != nil {
-				if val, ok := subStructMap[sub]; ok {
-					subName = val
-				} else {
-					//subName = fmt.Sprintf("%v_sub%v", structName, len(subStructMap)+1)
-					subName = FmtFieldName(key) // 使用字段名字
-					subStructMap[sub] = subName
-				}
-			}
-
-			valueType = subName
-		}
+	for _, key := range g.objectKeys(obj, path) {
+		value, optional, nullable := unwrapValue(obj[key])
+		fieldPath := subPath(path, key)
+		valueType := g.typeForValue(value, fieldPath)
 
 		fieldName := FmtFieldName(key)
 
-		tagList := make([]string, 0)
-		for _, t := range tags {
-			tagList = append(tagList, fmt.Sprintf("%s:\"%s\"", t, key))
+		// fields absent or null in some samples are pointers
+		if optional || nullable {
+			valueType = optionalType(valueType)
 		}
 
+		comment := g.comment(fieldPath)
+		if comment.head != "" {
+			structure += "\n" + goComment(comment.head)
+		}
 		structure += fmt.Sprintf("\n%s %s `%s`",
 			fieldName,
 			valueType,
-			strings.Join(tagList, " "))
+			structTags(g.tags, key, g.isOmitempty(optional)))
+		lastLineComment = comment.line != ""
+		if lastLineComment {
+			structure += " " + goComment(comment.line)
+		}
+	}
+	if lastLineComment {
+		structure += "\n" // the closing brace is not commented out by the line comment
 	}
 	return structure
 }
 
+// tags of the field, omitempty fields are tagged omitempty, xml attributes and text are tagged attr and chardata
+func structTags(tags []string, key string, omitempty bool) string {
+	tagList := make([]string, 0, len(tags))
+	for _, t := range tags {
+		value := key
+		if t == "xml" {
+			switch {
+			case key == xmlTextKey:
+				value = ",chardata"
+			case strings.HasPrefix(key, xmlAttrPrefix):
+				value = key[len(xmlAttrPrefix):] + ",attr"
+			}
+		}
+		if omitempty && omitemptyTags[t] {
+			value += ",omitempty"
+		}
+		tagList = append(tagList, fmt.Sprintf("%s:\"%s\"", t, value))
+	}
+	return strings.Join(tagList, " ")
+}
+
+// optional fields are pointers, except for types that can be nil
+func optionalType(valueType string) string {
+	if valueType == "interface{}" || valueType == "any" || valueType == "json.RawMessage" || strings.HasPrefix(valueType, "*") ||
+		strings.HasPrefix(valueType, "[]") || strings.HasPrefix(valueType, "map[") {
+		return valueType
+	}
+	return "*" + valueType
+}
+
 // FmtFieldName formats a string as a struct key
 //
 // Example:
@@ -380,38 +385,46 @@ func lintFieldName(name string) string {
 	return string(runes)
 }
 
-// generate an appropriate struct type entry
-func typeForValue(value interface{}, structName string, tags []string, subStructMap map[string]string, convertFloats bool) string {
+// generate an appropriate struct type entry, path is the path of the value
+func (g *generator) typeForValue(value interface{}, path string) string {
 	//Check if this is an array
 	if objects, ok := value.([]interface{}); ok {
-		types := make(map[reflect.Type]bool, 0)
-		for _, o := range objects {
-			types[reflect.TypeOf(o)] = true
-		}
-		if len(types) == 1 {
-			return "[]" + typeForValue(mergeElements(objects).([]interface{})[0], structName, tags, subStructMap, convertFloats)
+		if isUniformArray(objects) {
+			return "[]" + g.typeForValue(mergeElements(objects).([]interface{})[0], path)
 		}
-		return "[]interface{}"
+		return "[]" + g.unknownType()
 	} else if object, ok := value.(map[interface{}]interface{}); ok {
-		return generateTypes(convertKeysToStrings(object), structName, tags, 0, subStructMap, convertFloats) + "}"
+		return g.structType(convertKeysToStrings(object), path)
 	} else if object, ok := value.(map[string]interface{}); ok {
-		return generateTypes(object, structName, tags, 0, subStructMap, convertFloats) + "}"
+		return g.structType(object, path)
 	} else if reflect.TypeOf(value) == nil {
-		return "interface{}"
+		return g.unknownType()
+	}
+
+	switch value := value.(type) {
+	case time.Time: // yaml and toml timestamps
+		return "time.Time"
+	case json.Number:
+		if ForceFloats {
+			return "float64"
+		}
+		return numberType(value)
+	case string:
+		return g.stringType(value)
 	}
+
 	v := reflect.TypeOf(value).Name()
-	if v == "float64" && convertFloats {
+	if v == "float64" && g.convertFloats {
 		v = disambiguateFloatInt(value)
 	}
 	return v
 }
 
 // All numbers will initially be read as float64
-// If the number appears to be an integer value, use int instead
+// If the number is an integer value in the range of int64, use int64 instead
 func disambiguateFloatInt(value interface{}) string {
-	const epsilon = .0001
 	vfloat := value.(float64)
-	if !ForceFloats && math.Abs(vfloat-math.Floor(vfloat+epsilon)) < epsilon {
+	if !ForceFloats && vfloat == math.Trunc(vfloat) && vfloat >= math.MinInt64 && vfloat < math.MaxInt64 {
 		var tmp int64
 		return reflect.TypeOf(tmp).Name()
 	}
@@ -457,12 +470,33 @@ func mergeObjects(o1, o2 interface{}) interface{} {
 	}
 
 	if reflect.TypeOf(o1) != reflect.TypeOf(o2) {
-		return nil
+		return widenValue(o1, o2)
 	}
 
 	switch i := o1.(type) {
 	default:
 		return o1
+	case float64:
+		// 1 and 1.5 are both float64 in json, keep the float one to avoid being inferred as int
+		if hasFraction(o2) && !hasFraction(i) {
+			return o2
+		}
+		return o1
+	case json.Number:
+		if numberRank(o2.(json.Number)) > numberRank(i) {
+			return o2
+		}
+		return o1
+	case string:
+		// keep the plain string if the kinds are different, e.g. a time string and a plain string is string
+		k1, k2 := stringKind(i), stringKind(o2.(string))
+		switch {
+		case k1 == k2 || k1 == stringPlain:
+			return o1
+		case k2 == stringPlain:
+			return o2
+		}
+		return ""
 	case []interface{}:
 		if i2, ok := o2.([]interface{}); ok {
 			i3 := append(i, i2...)
@@ -473,7 +507,7 @@ func mergeObjects(o1, o2 interface{}) interface{} {
 		if i2, ok := o2.(map[string]interface{}); ok {
 			for k, v := range i2 {
 				if v2, ok := i[k]; ok {
-					i[k] = mergeObjects(v2, v)
+					i[k] = mergeField(v2, v)
 				} else {
 					i[k] = v
 				}
@@ -484,7 +518,7 @@ func mergeObjects(o1, o2 interface{}) interface{} {
 		if i2, ok := o2.(map[interface{}]interface{}); ok {
 			for k, v := range i2 {
 				if v2, ok := i[k]; ok {
-					i[k] = mergeObjects(v2, v)
+					i[k] = mergeField(v2, v)
 				} else {
 					i[k] = v
 				}
@@ -493,3 +527,59 @@ func mergeObjects(o1, o2 interface{}) interface{} {
 		return i
 	}
 }
+
+// widen conflicting types, int and float become float, string and number or bool become string,
+// other conflicts become nil, which is inferred as interface{}
+func widenValue(o1, o2 interface{}) interface{} {
+	if isNumber(o1) && isNumber(o2) {
+		if hasFraction(o2) || isFloat(o2) && !isFloat(o1) {
+			return o2
+		}
+		return o1
+	}
+
+	_, isStr1 := o1.(string)
+	_, isStr2 := o2.(string)
+	if isStr1 && isScalar(o2) {
+		return o1
+	}
+	if isStr2 && isScalar(o1) {
+		return o2
+	}
+
+	return nil
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
+
+func isFloat(v interface{}) bool {
+	switch v.(type) {
+	case float32, float64:
+		return true
+	}
+	return false
+}
+
+func isScalar(v interface{}) bool {
+	_, isStr := v.(string)
+	_, isBool := v.(bool)
+	return isStr || isBool || isNumber(v)
+}
+
+func hasFraction(v interface{}) bool {
+	switch v := v.(type) {
+	case float64:
+		return v != math.Trunc(v)
+	case float32:
+		return float64(v) != math.Trunc(float64(v))
+	case json.Number:
+		return numberType(v) == "float64"
+	}
+	return false
+}
@@ -0,0 +1,559 @@
+package jy2struct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// generate go types from a JSON Schema document
+type schemaGenerator struct {
+	root      map[string]interface{}
+	tags      []string
+	subStruct bool // whether nested objects are declared as types, referenced objects are always declared
+	options
+
+	refs    map[string]string // $ref -> go type
+	pending map[string]string // $ref being generated -> type name
+	used    map[string]bool   // used type names
+	bodies  map[string]string // type body -> type name, the same struct or enum is generated only once
+	types   map[string]string // type name -> type declaration
+	parents []string          // names of the structs being generated, nested types are named after the parent
+}
+
+// GenerateFromJSONSchema generate struct definitions from a JSON Schema document, supports properties, required,
+// enum, $ref/$defs, oneOf/anyOf, allOf and format hints. Required fields are values, optional and nullable fields
+// are pointers, optional fields are tagged omitempty. Nested types are named like the json and yaml sub structs,
+// after the key, then parent+key if the name is taken by a different type. If pkgName is not empty, the package clause and imports are added.
+// subStruct, WithParentPrefix, WithOmitempty and WithUnknownType work the same as Generate, referenced objects are always
+// declared as types, the detect options are not used, string types are detected by the format of the schema.
+func GenerateFromJSONSchema(input io.Reader, structName, pkgName string, tags []string, subStruct bool, opts ...Option) ([]byte, error) {
+	var iresult interface{}
+	if err := json.NewDecoder(input).Decode(&iresult); err != nil {
+		return nil, err
+	}
+	root, ok := iresult.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type: %T, json schema must be an object", iresult)
+	}
+
+	g := &schemaGenerator{
+		root:      root,
+		tags:      tags,
+		subStruct: subStruct,
+		options:   parseOption(opts),
+		refs:      make(map[string]string),
+		pending:   map[string]string{"#": structName},
+		used:      map[string]bool{structName: true},
+		bodies:    make(map[string]string),
+		types:     make(map[string]string),
+	}
+
+	valueType, err := g.typeFor(root, structName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	src, ok := g.types[structName]
+	if !ok {
+		src = fmt.Sprintf("type %s %s", structName, valueType)
+	}
+	src = "\n" + src
+
+	names := make([]string, 0, len(g.types))
+	for name := range g.types {
+		if name != structName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src = fmt.Sprintf("%v\n\n%v", src, g.types[name])
+	}
+
+	return formatSource(pkgName, src)
+}
+
+// go type of the schema, if fixed is true, the struct or enum is declared with exactly the name
+func (g *schemaGenerator) typeFor(schema map[string]interface{}, name string, fixed bool) (string, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		return g.refType(ref)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		if enumType := g.enumType(schema, enum, name, fixed); enumType != "" {
+			return enumType, nil
+		}
+	}
+
+	if allOf := schemaList(schema["allOf"]); len(allOf) > 0 {
+		merged, err := g.mergeSchemas(append([]map[string]interface{}{schema}, allOf...), true)
+		if err != nil {
+			return "", err
+		}
+		return g.typeFor(merged, name, fixed)
+	}
+
+	if variants := nonNullVariants(schema); len(variants) > 0 {
+		return g.oneOfType(variants, name, fixed)
+	}
+
+	typ, _ := schemaType(schema)
+	if typ == "" {
+		if _, ok := schema["properties"]; ok {
+			typ = "object"
+		}
+	}
+
+	switch typ {
+	case "object":
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			return g.structType(schema, props, name, fixed)
+		}
+		if ap, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			valueType, err := g.typeFor(ap, name+"Value", false)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + valueType, nil
+		}
+		return "map[string]" + g.unknownType(), nil
+	case "array":
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			itemType, err := g.typeFor(items, name, fixed)
+			if err != nil {
+				return "", err
+			}
+			return "[]" + itemType, nil
+		}
+		return "[]" + g.unknownType(), nil
+	case "string":
+		f, _ := schema["format"].(string)
+		return kindType(formatKinds[f]), nil
+	case "integer":
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	}
+
+	return g.unknownType(), nil
+}
+
+func (g *schemaGenerator) structType(schema map[string]interface{}, props map[string]interface{}, name string, fixed bool) (string, error) {
+	required := make(map[string]bool)
+	for _, v := range toList(schema["required"]) {
+		if key, ok := v.(string); ok {
+			required[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	isInline := !fixed && !g.subStruct
+	if !fixed && !isInline {
+		// reserve the name, so the nested types are named after it
+		name = g.uniqueName(name, g.parent())
+	}
+	g.parents = append(g.parents, name)
+	defer func() { g.parents = g.parents[:len(g.parents)-1] }()
+
+	structure := "struct {"
+	for _, key := range keys {
+		prop, _ := props[key].(map[string]interface{}) // true is any value
+		valueType, err := g.typeFor(prop, FmtFieldName(key), false)
+		if err != nil {
+			return "", err
+		}
+
+		if !required[key] || isNullable(prop) {
+			valueType = optionalType(valueType)
+		}
+
+		for _, line := range descriptionLines(prop) {
+			structure += "\n// " + line
+		}
+		structure += fmt.Sprintf("\n%s %s `%s`", FmtFieldName(key), valueType, structTags(g.tags, key, g.isOmitempty(!required[key])))
+	}
+	structure += "\n}"
+
+	if isInline {
+		return structure, nil
+	}
+
+	return g.declare(schema, name, fixed, structure), nil
+}
+
+// string or number enum is generated as a named type and constants, other enums are generated as the base type
+func (g *schemaGenerator) enumType(schema map[string]interface{}, enum []interface{}, name string, fixed bool) string {
+	values := make([]interface{}, 0, len(enum))
+	baseType, _ := schemaType(schema)
+	for _, v := range enum {
+		switch v := v.(type) {
+		case nil: // null is allowed by nullable enum
+			continue
+		case string:
+			if baseType == "" {
+				baseType = "string"
+			}
+			if baseType != "string" {
+				return ""
+			}
+		case float64:
+			if baseType == "" {
+				baseType = "integer"
+			}
+			if baseType == "integer" && v != math.Trunc(v) {
+				baseType = "number"
+			}
+			if baseType != "integer" && baseType != "number" {
+				return ""
+			}
+		default:
+			return ""
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	enum = values
+	goType := map[string]string{"string": "string", "integer": "int64", "number": "float64"}[baseType]
+
+	body := fmt.Sprintf("%s %v", goType, enum)
+	if typeName, ok := g.bodies[body]; ok && !fixed {
+		return typeName
+	}
+	if !fixed {
+		name = g.uniqueName(name, g.parent())
+	}
+
+	consts := make([]string, 0, len(enum))
+	constNames := make(map[string]bool)
+	for i, v := range enum {
+		var suffix, value string
+		switch v := v.(type) {
+		case string:
+			suffix, value = enumConstName(v), strconv.Quote(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+			suffix = strings.NewReplacer("-", "Minus", ".", "_").Replace(value)
+		}
+		constName := name + suffix
+		if suffix == "" || constNames[constName] {
+			constName = name + strconv.Itoa(i)
+		}
+		constNames[constName] = true
+		consts = append(consts, fmt.Sprintf("\t%s %s = %s", constName, name, value))
+	}
+
+	decl := fmt.Sprintf("type %s %s\n\nconst (\n%s\n)", name, goType, strings.Join(consts, "\n"))
+	g.types[name] = withDescription(schema, name, decl)
+	g.bodies[body] = name
+	return name
+}
+
+// oneOf/anyOf, a single variant is used directly, object variants are merged into one struct with all fields
+// optional, variants of the same type use the type, others are interface{}
+func (g *schemaGenerator) oneOfType(variants []map[string]interface{}, name string, fixed bool) (string, error) {
+	if len(variants) == 1 {
+		return g.typeFor(variants[0], name, fixed)
+	}
+
+	resolved := make([]map[string]interface{}, 0, len(variants))
+	isAllObjects := true
+	for _, v := range variants {
+		r, err := g.resolve(v)
+		if err != nil {
+			return "", err
+		}
+		resolved = append(resolved, r)
+		if typ, _ := schemaType(r); typ != "object" && r["properties"] == nil {
+			isAllObjects = false
+		}
+	}
+	if isAllObjects {
+		merged, err := g.mergeSchemas(resolved, false)
+		if err != nil {
+			return "", err
+		}
+		return g.typeFor(merged, name, fixed)
+	}
+
+	var valueType string
+	for _, v := range variants {
+		t, err := g.typeFor(v, name, false)
+		if err != nil {
+			return "", err
+		}
+		if valueType != "" && t != valueType {
+			return g.unknownType(), nil
+		}
+		valueType = t
+	}
+	return valueType, nil
+}
+
+// merge properties of the schemas into an object schema, required fields are kept only if keepRequired is true
+func (g *schemaGenerator) mergeSchemas(schemas []map[string]interface{}, keepRequired bool) (map[string]interface{}, error) {
+	props := make(map[string]interface{})
+	var required []interface{}
+	var description interface{}
+	for i, s := range schemas {
+		if i > 0 {
+			r, err := g.resolve(s)
+			if err != nil {
+				return nil, err
+			}
+			s = r
+		}
+		if p, ok := s["properties"].(map[string]interface{}); ok {
+			for k, v := range p {
+				props[k] = v
+			}
+		}
+		if keepRequired {
+			required = append(required, toList(s["required"])...)
+		}
+		if description == nil {
+			description = s["description"]
+		}
+	}
+
+	merged := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+	if description != nil {
+		merged["description"] = description
+	}
+	return merged, nil
+}
+
+// go type of the $ref, the referenced object is declared as a named type, the name is reserved only for objects,
+// other references are generated as the schema, e.g. a string, an array or an enum named after the reference
+func (g *schemaGenerator) refType(ref string) (string, error) {
+	if typeName, ok := g.refs[ref]; ok {
+		return typeName, nil
+	}
+	if typeName, ok := g.pending[ref]; ok {
+		if typeName == "" { // recursive reference that is not an object
+			return g.unknownType(), nil
+		}
+		// the reference is being generated, use pointer to avoid invalid recursive type
+		return "*" + typeName, nil
+	}
+
+	schema, err := g.lookup(ref)
+	if err != nil {
+		return "", err
+	}
+
+	name, fixed := FmtFieldName(ref[strings.LastIndex(ref, "/")+1:]), isObjectSchema(schema)
+	if fixed {
+		name = g.uniqueName(name, "")
+		g.pending[ref] = name
+	} else {
+		g.pending[ref] = ""
+	}
+	valueType, err := g.typeFor(schema, name, fixed)
+	if err != nil {
+		return "", err
+	}
+	delete(g.pending, ref)
+	g.refs[ref] = valueType
+	return valueType, nil
+}
+
+// the schema is generated as a struct, allOf is merged into an object
+func isObjectSchema(schema map[string]interface{}) bool {
+	if _, ok := schema["$ref"]; ok {
+		return false
+	}
+	if _, ok := schema["properties"].(map[string]interface{}); ok {
+		return true
+	}
+	return len(schemaList(schema["allOf"])) > 0
+}
+
+// resolve $ref of the schema
+func (g *schemaGenerator) resolve(schema map[string]interface{}) (map[string]interface{}, error) {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema, nil
+	}
+	return g.lookup(ref)
+}
+
+// find the schema by json pointer, only local references are supported, e.g. #/$defs/User
+func (g *schemaGenerator) lookup(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %s, only local references are supported", ref)
+	}
+
+	var current interface{} = g.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %s not found", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("$ref %s not found", ref)
+		}
+	}
+
+	schema, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %s is not a schema", ref)
+	}
+	return schema, nil
+}
+
+// declare the struct with the reserved name, the same struct body is declared only once unless the name is fixed
+func (g *schemaGenerator) declare(schema map[string]interface{}, name string, fixed bool, body string) string {
+	if typeName, ok := g.bodies[body]; ok && !fixed {
+		delete(g.used, name) // the reserved name is not needed
+		return typeName
+	}
+	g.types[name] = withDescription(schema, name, fmt.Sprintf("type %s %s", name, body))
+	if _, ok := g.bodies[body]; !ok {
+		g.bodies[body] = name
+	}
+	return name
+}
+
+// the type name that is not used, the same as the names of sub structs
+func (g *schemaGenerator) uniqueName(name string, parentName string) string {
+	if name == "" || name == "_" {
+		name = "Type"
+	}
+	return typeName(name, parentName, false, func(name string) bool {
+		if g.used[name] {
+			return false
+		}
+		g.used[name] = true
+		return true
+	})
+}
+
+// name of the struct being generated, empty at the top level
+func (g *schemaGenerator) parent() string {
+	if len(g.parents) == 0 {
+		return ""
+	}
+	return g.parents[len(g.parents)-1]
+}
+
+// type of the schema, the type may be an array, e.g. ["string", "null"]
+func schemaType(schema map[string]interface{}) (string, bool) {
+	switch t := schema["type"].(type) {
+	case string:
+		return t, t == "null"
+	case []interface{}:
+		typ, nullable := "", false
+		for _, v := range t {
+			if s, _ := v.(string); s == "null" {
+				nullable = true
+			} else if typ == "" {
+				typ = s
+			}
+		}
+		return typ, nullable
+	}
+	return "", false
+}
+
+func isNullable(schema map[string]interface{}) bool {
+	if _, nullable := schemaType(schema); nullable {
+		return true
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable { // openapi 3.0
+		return true
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		for _, v := range schemaList(schema[key]) {
+			if typ, _ := v["type"].(string); typ == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// variants of oneOf or anyOf except null
+func nonNullVariants(schema map[string]interface{}) []map[string]interface{} {
+	list := schemaList(schema["oneOf"])
+	if len(list) == 0 {
+		list = schemaList(schema["anyOf"])
+	}
+	variants := make([]map[string]interface{}, 0, len(list))
+	for _, v := range list {
+		if typ, _ := v["type"].(string); typ != "null" {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+func schemaList(v interface{}) []map[string]interface{} {
+	var list []map[string]interface{}
+	for _, item := range toList(v) {
+		if schema, ok := item.(map[string]interface{}); ok {
+			list = append(list, schema)
+		}
+	}
+	return list
+}
+
+func toList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func descriptionLines(schema map[string]interface{}) []string {
+	description, _ := schema["description"].(string)
+	var lines []string
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func withDescription(schema map[string]interface{}, name string, decl string) string {
+	lines := descriptionLines(schema)
+	if len(lines) == 0 {
+		return decl
+	}
+	return "// " + name + " " + strings.Join(lines, "\n// ") + "\n" + decl
+}
+
+// constant name suffix of the enum value, e.g. in-progress -> InProgress
+func enumConstName(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, value)
+	if strings.Trim(value, "_") == "" {
+		return ""
+	}
+	return FmtFieldName(value)
+}
@@ -0,0 +1,193 @@
+package jy2struct
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/huandu/xstrings"
+)
+
+// a scalar field of the config that can be loaded
+type loaderField struct {
+	goPath  string // e.g. Server.Port
+	keyPath string // e.g. server.port
+	env     string // e.g. APP_SERVER_PORT
+	goType  string
+	value   interface{} // value in the sample
+}
+
+// collect the scalar fields reachable through nested structs, fields in slices, maps and pointers are skipped
+func (g *generator) loaderFields(obj map[string]interface{}, goPath, keyPath, env string) []loaderField {
+	var fields []loaderField
+	for _, key := range sortedKeys(obj) {
+		value, optional, nullable := unwrapValue(obj[key])
+		if optional || nullable || value == nil {
+			continue
+		}
+
+		field := loaderField{
+			goPath:  joinPath(goPath, FmtFieldName(key)),
+			keyPath: joinPath(keyPath, key),
+			env:     joinEnv(env, key),
+			value:   value,
+		}
+		if sub, ok := toStringMap(value); ok {
+			fields = append(fields, g.loaderFields(sub, field.goPath, field.keyPath, field.env)...)
+			continue
+		}
+
+		field.goType = g.typeForValue(value, "")
+		if envParsers[field.goType] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// code to parse the environment variable v into the field type
+var envParsers = map[string]string{
+	"string":        "%[1]s = v",
+	"int":           "n, err := strconv.Atoi(v)\n%[2]s\n%[1]s = n",
+	"int64":         "n, err := strconv.ParseInt(v, 10, 64)\n%[2]s\n%[1]s = n",
+	"uint64":        "n, err := strconv.ParseUint(v, 10, 64)\n%[2]s\n%[1]s = n",
+	"float64":       "f, err := strconv.ParseFloat(v, 64)\n%[2]s\n%[1]s = f",
+	"bool":          "b, err := strconv.ParseBool(v)\n%[2]s\n%[1]s = b",
+	"time.Duration": "d, err := time.ParseDuration(v)\n%[2]s\n%[1]s = d",
+	"time.Time":     "t, err := time.Parse(time.RFC3339, v)\n%[2]s\n%[1]s = t",
+}
+
+// generate Load function, setDefaults, loadEnv and Validate methods of the config struct
+func (g *generator) loaderSource(obj map[string]interface{}, structName string) string {
+	fields := g.loaderFields(obj, "", "", g.EnvPrefix)
+
+	src := fmt.Sprintf(`
+
+// Load read the config from the yaml file, default values are taken from the sample,
+// the values are overridden by environment variables, e.g. %s
+func Load(path string) (*%s, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &%s{}
+	c.setDefaults()
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if err = c.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+`, envExample(fields), structName, structName)
+
+	src += fmt.Sprintf("\n// set the default values taken from the sample\nfunc (c *%s) setDefaults() {", structName)
+	for _, f := range fields {
+		if literal := defaultLiteral(f); literal != "" {
+			src += fmt.Sprintf("\nc.%s = %s", f.goPath, literal)
+		}
+	}
+	src += "\n}\n"
+
+	src += fmt.Sprintf("\n// override the values by environment variables\nfunc (c *%s) loadEnv() error {", structName)
+	for _, f := range fields {
+		onError := fmt.Sprintf("if err != nil {\nreturn fmt.Errorf(\"invalid %s: %%v\", err)\n}", f.env)
+		src += fmt.Sprintf("\nif v, ok := os.LookupEnv(%q); ok {\n%s\n}", f.env, fmt.Sprintf(envParsers[f.goType], "c."+f.goPath, onError))
+	}
+	src += "\nreturn nil\n}\n"
+
+	src += fmt.Sprintf("\n// Validate check the required fields, string fields that are not empty in the sample are required\nfunc (c *%s) Validate() error {", structName)
+	for _, f := range fields {
+		if s, ok := f.value.(string); ok && s != "" && f.goType == "string" {
+			src += fmt.Sprintf("\nif c.%s == \"\" {\nreturn errors.New(%q)\n}", f.goPath, f.keyPath+" is required")
+		}
+	}
+	src += "\nreturn nil\n}\n"
+
+	return src
+}
+
+// go literal of the sample value, zero values are skipped
+func defaultLiteral(f loaderField) string {
+	switch v := f.value.(type) {
+	case string:
+		switch f.goType {
+		case "string":
+			if v != "" {
+				return strconv.Quote(v)
+			}
+		case "time.Duration":
+			if d, err := time.ParseDuration(v); err == nil && d != 0 {
+				return durationLiteral(d)
+			}
+		}
+	case bool:
+		if v {
+			return "true"
+		}
+	case float64:
+		if v != 0 {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	case int, int64, uint64:
+		if s := fmt.Sprint(v); s != "0" {
+			return s
+		}
+	}
+	return ""
+}
+
+// e.g. 90 * time.Minute
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("%d", d)
+}
+
+func envExample(fields []loaderField) string {
+	if len(fields) == 0 {
+		return "APP_SERVER_PORT"
+	}
+	return fields[0].env
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// e.g. APP + maxConns -> APP_MAX_CONNS
+func joinEnv(env string, key string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, xstrings.ToSnakeCase(key))
+	if env == "" {
+		return name
+	}
+	return env + "_" + name
+}
@@ -0,0 +1,149 @@
+package jy2struct
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// a nested object to be named
+type structNode struct {
+	obj  map[string]interface{}
+	path string
+	name string
+}
+
+// name the sub structs in breadth-first order, so shallow structs get the short names and the names do not depend
+// on the generation order. A sub struct is named after its key, if the name is taken by a different struct, it is
+// named after parent+key, then parent+key with a numeric suffix.
+func (g *generator) nameSubStructs(value interface{}, rootName string) {
+	root, ok := elementObject(value)
+	if !g.subStruct || !ok {
+		return
+	}
+
+	g.structBodies[rootName] = g.inlineBody(root)
+	queue := []structNode{{obj: root, name: rootName}}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, key := range sortedKeys(node.obj) {
+			v, _, _ := unwrapValue(node.obj[key])
+			obj, ok := elementObject(v)
+			if !ok {
+				continue
+			}
+			path := subPath(node.path, key)
+			name := g.subStructName(key, node.name, g.inlineBody(obj))
+			g.structNames[path] = name
+			queue = append(queue, structNode{obj: obj, path: path, name: name})
+		}
+	}
+}
+
+func (g *generator) subStructName(key string, parentName string, body string) string {
+	return typeName(FmtFieldName(key), parentName, g.ParentPrefix, func(name string) bool {
+		return g.useName(name, body)
+	})
+}
+
+// the first name that can be used in name, parentName+name, parentName+name with a numeric suffix,
+// if parentPrefix is true, name is skipped
+func typeName(name string, parentName string, parentPrefix bool, use func(name string) bool) string {
+	candidates := []string{name, parentName + name}
+	if parentPrefix || parentName == "" {
+		candidates = candidates[1:]
+	}
+	for _, candidate := range candidates {
+		if use(candidate) {
+			return candidate
+		}
+	}
+
+	last := candidates[len(candidates)-1]
+	for i := 2; ; i++ {
+		candidate := last + strconv.Itoa(i)
+		if use(candidate) {
+			return candidate
+		}
+	}
+}
+
+// the name can be used if it is not taken or taken by the same struct
+func (g *generator) useName(name string, body string) bool {
+	if b, ok := g.structBodies[name]; ok {
+		return b == body
+	}
+	g.structBodies[name] = body
+	return true
+}
+
+// the struct body with inline sub structs, the same body means the same struct
+func (g *generator) inlineBody(obj map[string]interface{}) string {
+	inline := *g
+	inline.subStruct = false
+	inline.doc = nil
+	return inline.generateTypes(obj, "") + "}"
+}
+
+// the type of the object, it is the sub struct name if sub structs are separated
+func (g *generator) structType(obj map[string]interface{}, path string) string {
+	body := g.generateTypes(obj, path) + "}"
+	if name, ok := g.structNames[path]; ok && g.subStruct {
+		g.subStructs[name] = body
+		return name
+	}
+	return body
+}
+
+func (g *generator) subStructsSource() string {
+	names := make([]string, 0, len(g.subStructs))
+	for name := range g.subStructs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	src := ""
+	for _, name := range names {
+		src += fmt.Sprintf("\n\ntype %s %s", name, g.subStructs[name])
+	}
+	return src
+}
+
+// the object of the value, or the object of the elements of the array
+func elementObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return convertKeysToStrings(v), true
+	case []interface{}:
+		if isUniformArray(v) {
+			return elementObject(mergeElements(v).([]interface{})[0])
+		}
+	}
+	return nil, false
+}
+
+// the elements of the array are the same type
+func isUniformArray(objects []interface{}) bool {
+	types := make(map[reflect.Type]bool)
+	for _, o := range objects {
+		types[reflect.TypeOf(o)] = true
+	}
+	return len(types) == 1
+}
+
+func subPath(path string, key string) string {
+	return path + "\x00" + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package jy2struct
+
+// OmitemptyStyle which fields are tagged omitempty
+type OmitemptyStyle int
+
+// nolint
+const (
+	OmitemptyOptional OmitemptyStyle = iota // fields absent in some samples
+	OmitemptyAll
+	OmitemptyNone
+)
+
+// unknown types, the type of null values and elements of mixed arrays
+const (
+	UnknownInterface  = "interface{}"
+	UnknownAny        = "any"
+	UnknownRawMessage = "json.RawMessage"
+)
+
+// Option function
+type Option func(*options)
+
+type options struct {
+	DetectTime     bool // RFC3339 string is time.Time
+	DetectDuration bool // duration string such as 1h30m is time.Duration
+	DetectUUID     bool // uuid string is uuid.UUID of github.com/google/uuid
+	ParentPrefix   bool // sub struct is always named after parent+key
+	Omitempty      OmitemptyStyle
+	UnknownType    string
+	KeepOrder      bool   // fields are in the order of the document instead of sorted order
+	Loader         bool   // generate Load function with env overrides, defaults and Validate method
+	EnvPrefix      string // prefix of the environment variables used by Load
+}
+
+var defaultOptions = options{
+	Omitempty:   OmitemptyOptional,
+	UnknownType: UnknownInterface,
+}
+
+// WithDetectTime detect RFC3339 string as time.Time, e.g. 2022-10-01T10:00:00Z
+func WithDetectTime() Option {
+	return func(o *options) {
+		o.DetectTime = true
+	}
+}
+
+// WithDetectDuration detect duration string as time.Duration, e.g. 1h30m, 500ms.
+// note that encoding/json can not decode duration string, use a decoder that supports it, such as yaml.v3
+func WithDetectDuration() Option {
+	return func(o *options) {
+		o.DetectDuration = true
+	}
+}
+
+// WithDetectUUID detect uuid string as uuid.UUID of github.com/google/uuid
+func WithDetectUUID() Option {
+	return func(o *options) {
+		o.DetectUUID = true
+	}
+}
+
+// WithParentPrefix name the sub struct after parent+key, e.g. the struct of key address in User is UserAddress,
+// by default, it is named after key, parent+key is used only when the name is taken by a different struct
+func WithParentPrefix() Option {
+	return func(o *options) {
+		o.ParentPrefix = true
+	}
+}
+
+// WithOmitempty set which fields are tagged omitempty, default is OmitemptyOptional
+func WithOmitempty(s OmitemptyStyle) Option {
+	return func(o *options) {
+		o.Omitempty = s
+	}
+}
+
+// WithUnknownType set the type of unknown values, UnknownInterface(default), UnknownAny or UnknownRawMessage
+func WithUnknownType(t string) Option {
+	return func(o *options) {
+		o.UnknownType = t
+	}
+}
+
+// WithKeepOrder keep the fields in the order of the keys in the document, by default, fields are sorted by key
+// for reproducibility. The document must be parsed by a parser that records the key order, such as
+// ParseJSONWithOrder and ParseYamlWithComments
+func WithKeepOrder() Option {
+	return func(o *options) {
+		o.KeepOrder = true
+	}
+}
+
+// WithLoader generate Load(path string) function that reads the yaml config file, besides the struct,
+// default values are taken from the sample, values can be overridden by environment variables named after
+// the field path with envPrefix, e.g. APP_SERVER_PORT, string fields not empty in the sample are checked by Validate
+func WithLoader(envPrefix string) Option {
+	return func(o *options) {
+		o.Loader = true
+		o.EnvPrefix = envPrefix
+	}
+}
+
+// whether the field is tagged omitempty, optional is true if the field is absent in some samples or not required
+func (o options) isOmitempty(optional bool) bool {
+	switch o.Omitempty {
+	case OmitemptyAll:
+		return true
+	case OmitemptyNone:
+		return false
+	}
+	return optional
+}
+
+func (o options) unknownType() string {
+	if o.UnknownType == "" {
+		return UnknownInterface
+	}
+	return o.UnknownType
+}
+
+func parseOption(options []Option) options {
+	o := defaultOptions
+	for _, f := range options {
+		f(&o)
+	}
+	return o
+}
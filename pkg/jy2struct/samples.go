@@ -0,0 +1,222 @@
+package jy2struct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// samples documents parsed from NDJSON or multiple files, all of them are merged into one struct
+type samples []interface{}
+
+// mergedValue a value merged from multiple samples
+type mergedValue struct {
+	value    interface{}
+	optional bool // absent in some samples
+	nullable bool // null in some samples
+}
+
+// ParseNDJSON parse newline delimited json or concatenated json documents, a document that is an array of objects
+// is treated as multiple documents
+func ParseNDJSON(input io.Reader) (interface{}, error) {
+	var result samples
+	decoder := json.NewDecoder(input)
+	decoder.UseNumber()
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = appendSamples(result, doc)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no json document found")
+	}
+	return result, nil
+}
+
+func appendSamples(s samples, doc interface{}) samples {
+	if docs, ok := doc.([]interface{}); ok && isAllObjects(docs) {
+		return append(s, docs...)
+	}
+	return append(s, doc)
+}
+
+// read all json files in the directory as samples
+func readSampleDir(dir string, parser Parser) (samples, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".ndjson" && ext != ".jsonl") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+
+	var result samples
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := parser(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parse %s error: %v", file, err)
+		}
+		if doc, ok := docs.(*document); ok {
+			docs = doc.value // the key order of samples is not kept
+		}
+		if s, ok := docs.(samples); ok {
+			result = append(result, s...)
+		} else {
+			result = appendSamples(result, docs)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no json file found in %s", dir)
+	}
+	return result, nil
+}
+
+// merge all samples into one object, fields absent in some samples are marked as optional
+func mergeSamples(s samples) (map[string]interface{}, error) {
+	objects := make([]map[string]interface{}, 0, len(s))
+	for _, v := range s {
+		obj, ok := toStringMap(v)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type: %T, every sample must be an object", v)
+		}
+		objects = append(objects, obj)
+	}
+	return mergeSampleObjects(objects), nil
+}
+
+func mergeSampleObjects(objects []map[string]interface{}) map[string]interface{} {
+	values := make(map[string][]interface{})
+	for _, obj := range objects {
+		for k, v := range obj {
+			values[k] = append(values[k], v)
+		}
+	}
+
+	result := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		value := mergeSampleValues(vs)
+		optional, nullable := len(vs) < len(objects), value != nil && hasNil(vs)
+		if optional || nullable {
+			value = mergedValue{value: value, optional: optional, nullable: nullable}
+		}
+		result[k] = value
+	}
+	return result
+}
+
+// nested objects and arrays of objects are merged as samples too, other values are widened by mergeObjects
+func mergeSampleValues(vs []interface{}) interface{} {
+	var objects []map[string]interface{}
+	var elements []interface{}
+	nonNil, arrays := 0, 0
+	for _, v := range vs {
+		if v == nil {
+			continue
+		}
+		nonNil++
+		if obj, ok := toStringMap(v); ok {
+			objects = append(objects, obj)
+		} else if arr, ok := v.([]interface{}); ok {
+			arrays++
+			elements = append(elements, arr...)
+		}
+	}
+
+	switch {
+	case nonNil == 0:
+		return nil
+	case len(objects) == nonNil:
+		return mergeSampleObjects(objects)
+	case arrays == nonNil:
+		if !isAllObjects(elements) {
+			return elements
+		}
+		objs := make([]map[string]interface{}, 0, len(elements))
+		for _, e := range elements {
+			obj, _ := toStringMap(e)
+			objs = append(objs, obj)
+		}
+		return []interface{}{mergeSampleObjects(objs)}
+	}
+
+	var merged interface{}
+	for _, v := range vs {
+		merged = mergeObjects(merged, v)
+	}
+	return merged
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return convertKeysToStrings(v), true
+	}
+	return nil, false
+}
+
+func isAllObjects(vs []interface{}) bool {
+	if len(vs) == 0 {
+		return false
+	}
+	for _, v := range vs {
+		if _, ok := toStringMap(v); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func hasNil(vs []interface{}) bool {
+	for _, v := range vs {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrap the merged value, return the value and whether it is optional and nullable
+func unwrapValue(value interface{}) (interface{}, bool, bool) {
+	if mv, ok := value.(mergedValue); ok {
+		return mv.value, mv.optional, mv.nullable
+	}
+	return value, false, false
+}
+
+// merge the values of a field in objects, the field is nullable if it is null in some of the objects
+func mergeField(o1, o2 interface{}) interface{} {
+	v1, optional1, nullable1 := unwrapValue(o1)
+	v2, optional2, nullable2 := unwrapValue(o2)
+	merged := mergeObjects(v1, v2)
+	optional := optional1 || optional2
+	nullable := merged != nil && (nullable1 || nullable2 || v1 == nil || v2 == nil)
+	if optional || nullable {
+		return mergedValue{value: merged, optional: optional, nullable: nullable}
+	}
+	return merged
+}
@@ -0,0 +1,125 @@
+package jy2struct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+var tsIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTS generate typescript interfaces given a JSON or YAML representation of an object and a name interfaceName.
+// null values and fields absent in some samples are generated as optional fields,
+// fields null in some samples are generated as nullable fields.
+func GenerateTS(input io.Reader, parser Parser, interfaceName string) ([]byte, error) {
+	iresult, err := parser(input)
+	if err != nil {
+		return nil, err
+	}
+	if doc, ok := iresult.(*document); ok {
+		iresult = doc.value
+	}
+
+	// sub interfaces are named the same way as the go sub structs
+	g := &generator{
+		subStruct:    true,
+		structNames:  make(map[string]string),
+		structBodies: make(map[string]string),
+		subStructs:   make(map[string]string),
+	}
+	var src string
+
+	switch iresult := iresult.(type) {
+	case samples:
+		obj, err := mergeSamples(iresult)
+		if err != nil {
+			return nil, err
+		}
+		g.nameSubStructs(obj, interfaceName)
+		src = fmt.Sprintf("export interface %s %s\n", interfaceName, g.generateTSTypes(obj, ""))
+	case map[interface{}]interface{}:
+		obj := convertKeysToStrings(iresult)
+		g.nameSubStructs(obj, interfaceName)
+		src = fmt.Sprintf("export interface %s %s\n", interfaceName, g.generateTSTypes(obj, ""))
+	case map[string]interface{}:
+		g.nameSubStructs(iresult, interfaceName)
+		src = fmt.Sprintf("export interface %s %s\n", interfaceName, g.generateTSTypes(iresult, ""))
+	case []interface{}:
+		g.nameSubStructs(iresult, interfaceName)
+		src = fmt.Sprintf("export type %s = %s;\n", interfaceName, g.tsTypeForValue(iresult, ""))
+	default:
+		return nil, fmt.Errorf("unexpected type: %T", iresult)
+	}
+
+	// 补上子接口
+	names := make([]string, 0, len(g.subStructs))
+	for name := range g.subStructs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src += fmt.Sprintf("\nexport interface %s %s\n", name, g.subStructs[name])
+	}
+
+	return []byte(src), nil
+}
+
+// Generate typescript interface entries for a map[string]interface{} structure, path is the path of the object
+func (g *generator) generateTSTypes(obj map[string]interface{}, path string) string {
+	structure := "{"
+	for _, key := range sortedKeys(obj) {
+		value, isOptional, isNullable := unwrapValue(obj[key])
+		optional := ""
+		if value == nil || isOptional {
+			optional = "?"
+		}
+		valueType := g.tsTypeForValue(value, subPath(path, key))
+		if isNullable {
+			valueType += " | null"
+		}
+		structure += fmt.Sprintf("\n  %s%s: %s;", tsFieldName(key), optional, valueType)
+	}
+	return structure + "\n}"
+}
+
+// generate an appropriate typescript type entry, nested objects are extracted as sub interfaces
+func (g *generator) tsTypeForValue(value interface{}, path string) string {
+	switch value := value.(type) {
+	case []interface{}:
+		if isUniformArray(value) {
+			return g.tsTypeForValue(mergeElements(value).([]interface{})[0], path) + "[]"
+		}
+		return "unknown[]"
+	case map[interface{}]interface{}:
+		return g.tsSubInterface(convertKeysToStrings(value), path)
+	case map[string]interface{}:
+		return g.tsSubInterface(value, path)
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return "number"
+	}
+	return "unknown"
+}
+
+func (g *generator) tsSubInterface(obj map[string]interface{}, path string) string {
+	body := g.generateTSTypes(obj, path)
+	name, ok := g.structNames[path]
+	if !ok {
+		return body
+	}
+	g.subStructs[name] = body
+	return name
+}
+
+// keys that are not valid identifiers must be quoted
+func tsFieldName(key string) string {
+	if tsIdentifierRegexp.MatchString(key) {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
@@ -0,0 +1,26 @@
+package replacer
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// 检测二进制文件时读取的最大字节数，和git相同
+const binarySniffLen = 8000
+
+// SetReplaceBinary 设置是否替换二进制文件的内容，默认不替换，二进制文件原样复制，文件名和文件夹名仍然替换
+func (r *replacerInfo) SetReplaceBinary(replaceBinary bool) {
+	r.replaceBinary = replaceBinary
+}
+
+// 判断是否二进制文件，包含NUL字节或者MIME类型不是文本，例如图片、压缩包、编译后的文件
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	return !strings.HasPrefix(http.DetectContentType(data), "text/")
+}
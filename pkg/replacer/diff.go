@@ -0,0 +1,328 @@
+package replacer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// 每个变化块前后显示的上下文行数
+	diffContextLines = 3
+
+	// 最大编辑距离，超过时不再查找最短编辑序列，整体删除旧行并插入新行，内存为O(D^2)
+	diffMaxEditDistance = 1000
+)
+
+// FileChange 预览文件替换结果
+type FileChange struct {
+	OldPath      string        // 原文件相对路径
+	NewPath      string        // 替换后的文件相对路径，文件名或文件夹名被替换时和OldPath不同
+	Replacements []Replacement // 文件内容中模块路径和每个替换字段的替换次数，不包括次数为0的字段
+	Diff         string        // 内容和路径变化的unified diff
+}
+
+// Replacement 替换字段的替换次数
+type Replacement struct {
+	Old   string `json:"old"`   // 模板字段
+	New   string `json:"new"`   // 新字段
+	Count int    `json:"count"` // 替换次数
+}
+
+// Count 文件内容的总替换次数
+func (c *FileChange) Count() int {
+	total := 0
+	for _, rep := range c.Replacements {
+		total += rep.Count
+	}
+	return total
+}
+
+// DryRun 预览替换结果，不写入任何文件，返回内容或路径有变化的文件，部分文件失败时仍然返回其他文件的结果
+func (r *replacerInfo) DryRun() ([]FileChange, error) {
+	var mu sync.Mutex
+	changes := make(map[int]FileChange)
+	err := r.replaceFiles(func(f *replacedFile) error {
+		if f.oldFile == f.newFile && string(f.oldData) == string(f.data) {
+			return nil
+		}
+
+		change := FileChange{
+			OldPath: filepath.ToSlash(strings.TrimPrefix(f.oldFile, "/")),
+			NewPath: filepath.ToSlash(strings.TrimPrefix(f.newFile, "/")),
+		}
+		change.Replacements = r.fileReplacements(f)
+		if f.isBinary {
+			change.Diff = binaryDiff(change.OldPath, change.NewPath, string(f.oldData) != string(f.data))
+		} else {
+			change.Diff = unifiedDiff(change.OldPath, change.NewPath, string(f.oldData), string(f.data))
+		}
+		mu.Lock()
+		changes[f.index] = change
+		mu.Unlock()
+		return nil
+	})
+
+	// 按文件顺序排列
+	indexes := make([]int, 0, len(changes))
+	for i := range changes {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	sorted := make([]FileChange, 0, len(changes))
+	for _, i := range indexes {
+		sorted = append(sorted, changes[i])
+	}
+	return sorted, err
+}
+
+// 生成git风格的unified diff，包括重命名信息和内容变化
+func unifiedDiff(oldPath string, newPath string, oldText string, newText string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", oldPath, newPath)
+	if oldPath != newPath {
+		fmt.Fprintf(&sb, "rename from %s\nrename to %s\n", oldPath, newPath)
+	}
+	if oldText == newText {
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", oldPath, newPath)
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	for _, h := range diffHunks(ops) {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// 二进制文件只显示是否变化，不显示内容
+func binaryDiff(oldPath string, newPath string, changed bool) string {
+	diff := unifiedDiff(oldPath, newPath, "", "")
+	if changed {
+		diff += fmt.Sprintf("Binary files a/%s and b/%s differ\n", oldPath, newPath)
+	}
+	return diff
+}
+
+// 按行分割，保留换行符，用来区分最后一行是否有换行符
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// 行变化类型
+const (
+	opEqual  = ' '
+	opDelete = '-'
+	opInsert = '+'
+)
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// 使用Myers算法比较两组行，返回最短的编辑序列
+func diffLines(a []string, b []string) []diffOp {
+	// 去掉相同的前缀和后缀，减少比较的行数
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{opEqual, line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{opEqual, line})
+	}
+	return ops
+}
+
+func myersDiff(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+	if maxD > diffMaxEditDistance {
+		maxD = diffMaxEditDistance
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int // 第d步开始时对角线-d-1到d+1的状态
+
+	// 前向搜索，记录每一步的状态用于回溯
+	found := false
+loop:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // 向下移动，插入
+			} else {
+				x = v[offset+k-1] + 1 // 向右移动，删除
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break loop
+			}
+		}
+	}
+	if !found {
+		return replaceAllLines(a, b)
+	}
+
+	// 从终点回溯编辑路径
+	ops := make([]diffOp, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v, offset := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{opEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{opInsert, b[y-1]})
+			} else {
+				ops = append(ops, diffOp{opDelete, a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	// 回溯得到的是倒序
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// 删除所有旧行，插入所有新行
+func replaceAllLines(a []string, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, diffOp{opDelete, line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{opInsert, line})
+	}
+	return ops
+}
+
+// 把编辑序列分成多个变化块，每个变化块前后保留diffContextLines行上下文
+func diffHunks(ops []diffOp) []string {
+	var hunks []string
+	oldLine, newLine := 1, 1 // 当前行号
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// 变化块的起始位置，包括前面的上下文
+		start := i
+		for start > 0 && i-start < diffContextLines && ops[start-1].kind == opEqual {
+			start--
+		}
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+
+		// 变化块的结束位置，相邻变化之间的相同行不超过2*diffContextLines时合并为一个变化块
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			equal := 0
+			for end+equal < len(ops) && ops[end+equal].kind == opEqual {
+				equal++
+			}
+			if end+equal == len(ops) || equal > 2*diffContextLines {
+				if equal > diffContextLines {
+					equal = diffContextLines
+				}
+				end += equal
+				break
+			}
+			end += equal
+		}
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case opEqual:
+				oldCount++
+				newCount++
+			case opDelete:
+				oldCount++
+			case opInsert:
+				newCount++
+			}
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		hunks = append(hunks, fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount), body.String()))
+
+		// 更新行号，跳过已输出的行
+		for _, op := range ops[i:end] {
+			if op.kind != opInsert {
+				oldLine++
+			}
+			if op.kind != opDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return hunks
+}
+
+// 变化块的行范围，例如 1,3，没有行时起始行号是前一行
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,119 @@
+package replacer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// SetGoAware 设置go文件只替换标识符、import路径和包名，不替换字符串和注释，替换后格式化代码，
+// 非go文件和解析失败的go文件仍然按文本替换
+func (r *replacerInfo) SetGoAware(goAware bool) {
+	r.goAware = goAware
+}
+
+func isGoFile(file string) bool {
+	return strings.HasSuffix(file, ".go")
+}
+
+// 替换go代码中的标识符、import路径和包名，返回替换后的代码和每个字段的替换次数，没有替换时返回原代码
+func replaceGoCode(data []byte, matchers []*fieldMatcher) ([]byte, []int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", data, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := make([]int, len(matchers))
+	changed := false
+	replace := func(s string) string {
+		newValue, c := replaceAll([]byte(s), matchers)
+		for i, n := range c {
+			if n > 0 {
+				counts[i] += n
+				changed = true
+			}
+		}
+		return string(newValue)
+	}
+
+	keptImports := keptImportNames(file, matchers)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			// 引用的包不替换时，包中的名称也不替换，例如other.OldName
+			if x, ok := node.X.(*ast.Ident); ok && x.Obj == nil && keptImports[x.Name] {
+				x.Name = replace(x.Name)
+				return false
+			}
+		case *ast.Ident: // 包括包名
+			node.Name = replace(node.Name)
+		case *ast.ImportSpec:
+			if path, err := strconv.Unquote(node.Path.Value); err == nil {
+				node.Path.Value = strconv.Quote(replace(path))
+			}
+		}
+		return true
+	})
+	if !changed {
+		return data, counts, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = format.Node(buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), counts, nil
+}
+
+// import路径不替换的包在代码中的名称，没有别名时使用import路径的最后一个元素，例如gopkg.in/yaml.v3是yaml
+func keptImportNames(file *ast.File, matchers []*fieldMatcher) map[string]bool {
+	names := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if newPath, _ := replaceAll([]byte(path), matchers); string(newPath) != path {
+			continue
+		}
+
+		var name string
+		if spec.Name != nil {
+			name = spec.Name.Name
+		} else {
+			name = importName(path)
+		}
+		if name != "_" && name != "." {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// 根据import路径推断包名，忽略版本后缀，例如github.com/go-redis/redis/v8是redis，gopkg.in/yaml.v3是yaml
+func importName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// 主版本号，例如v2
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
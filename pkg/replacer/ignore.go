@@ -0,0 +1,184 @@
+package replacer
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 默认读取的忽略规则文件
+var defaultIgnoreFiles = []string{".gitignore", ".gotoolignore"}
+
+// 忽略规则，格式和.gitignore相同
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool // 以!开头，不忽略匹配的路径
+	dirOnly bool // 以/结尾，只匹配目录
+}
+
+// 解析忽略规则，例如 *.pb.go、**/testdata、docs/**、/build/、!keep.go，
+// 不包含/的规则匹配任意层级的文件名或目录名，包含/的规则匹配相对于源目录的路径
+func newIgnoreRule(pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	expr := globToRegexp(strings.TrimPrefix(pattern, "/"))
+	if !strings.Contains(pattern, "/") {
+		expr = "(.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil { // 不合法的glob按普通字符匹配
+		re = regexp.MustCompile("^" + regexp.QuoteMeta(pattern) + "$")
+	}
+	rule.re = re
+	return rule, true
+}
+
+// glob转为正则表达式，**匹配任意层级目录，*和?不匹配/
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+func parseIgnoreRules(data []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if rule, ok := newIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// 最后一条匹配的规则决定是否忽略，返回是否匹配和是否忽略
+func matchRules(rules []ignoreRule, relPath string, isDir bool) (bool, bool) {
+	matched, ignored := false, false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(relPath) {
+			matched, ignored = true, !rule.negate
+		}
+	}
+	return matched, ignored
+}
+
+// 文件所在的所有目录，例如a/b/c.go返回a和a/b
+func parentDirs(relPath string) []string {
+	var dirs []string
+	for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// LoadIgnoreFiles 读取源目录下.gitignore格式的忽略规则文件，默认读取.gitignore和.gotoolignore，文件不存在时跳过
+func (r *replacerInfo) LoadIgnoreFiles(filenames ...string) error {
+	if len(filenames) == 0 {
+		filenames = defaultIgnoreFiles
+	}
+	if r.isActual {
+		if info, err := os.Stat(r.path); err != nil || !info.IsDir() { // 源路径是文件
+			return nil
+		}
+	}
+
+	for _, filename := range filenames {
+		var data []byte
+		var err error
+		if r.isActual {
+			data, err = os.ReadFile(filepath.Join(r.path, filename))
+		} else {
+			data, err = r.fs.ReadFile(r.path + "/" + filename)
+		}
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		r.ignoreRules = append(r.ignoreRules, parseIgnoreRules(data)...)
+	}
+	return nil
+}
+
+// 文件相对于源目录的路径，使用/分隔
+func (r *replacerInfo) relPath(file string) string {
+	return strings.TrimPrefix(filepath.ToSlash(strings.Replace(file, r.path, "", 1)), "/")
+}
+
+// 判断文件是否忽略处理，所在目录被忽略时，文件也被忽略，返回忽略的原因和被忽略的路径，
+// 被忽略的是目录时路径以/结尾，不忽略时返回空字符串
+func (r *replacerInfo) ignoreReason(file string) (string, string) {
+	relPath := r.relPath(file)
+
+	for _, dir := range parentDirs(relPath) {
+		if matched, _ := matchRules(r.ignoreDirs, dir, true); matched {
+			return SkipIgnoredDir, dir + "/"
+		}
+		if _, ignored := matchRules(r.ignoreRules, dir, true); ignored {
+			return SkipIgnoreRule, dir + "/"
+		}
+	}
+
+	if matched, _ := matchRules(r.ignoreFiles, relPath, false); matched {
+		return SkipIgnoredFile, relPath
+	}
+	if _, ignored := matchRules(r.ignoreRules, relPath, false); ignored {
+		return SkipIgnoreRule, relPath
+	}
+	return "", ""
+}
@@ -0,0 +1,310 @@
+package replacer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 备份文件后缀
+const backupSuffix = ".bak"
+
+// SetInPlace 设置在源目录替换文件内容、文件名和文件夹名，不再输出到新目录，backup为true时原文件备份为.bak文件，
+// 替换失败时回滚所有已修改的文件
+func (r *replacerInfo) SetInPlace(backup bool) {
+	r.inPlace = true
+	r.backup = backup
+}
+
+// 在源目录替换，先并发计算所有文件的替换结果并写入临时文件，再逐个移动到目标位置，失败时按记录回滚
+func (r *replacerInfo) saveInPlace() error {
+	if !r.isActual {
+		return errors.New("in-place replace only supports files in local directory")
+	}
+
+	var mu sync.Mutex
+	var files []*replacedFile
+	err := r.replaceFiles(func(f *replacedFile) error {
+		isChanged := !f.copyOnly && string(f.oldData) != string(f.data)
+		if f.oldFile == f.newFile && !isChanged {
+			return nil
+		}
+
+		// 只保留路径，替换后的内容保存到原文件所在目录的临时文件
+		saved := &replacedFile{index: f.index, file: f.file, oldFile: f.oldFile, newFile: f.newFile, mode: f.mode}
+		if isChanged {
+			tmpFile, err := writeTempFile(f.file, bytes.NewReader(f.data), f.mode)
+			if err != nil {
+				return err
+			}
+			saved.tmpFile = tmpFile
+		}
+		mu.Lock()
+		files = append(files, saved)
+		mu.Unlock()
+		return nil
+	})
+	defer func() { // 删除没有移动到目标位置的临时文件
+		for _, f := range files {
+			if f.tmpFile != "" {
+				_ = os.Remove(f.tmpFile)
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].index < files[j].index })
+
+	j := &journal{}
+	oldDirs := make(map[string]struct{})
+	for _, f := range files {
+		if err = r.saveFileInPlace(f, j); err != nil {
+			if rollbackErr := j.rollback(); rollbackErr != nil {
+				return fmt.Errorf("%v, %v", err, rollbackErr)
+			}
+			return err
+		}
+		if f.oldFile != f.newFile {
+			oldDirs[filepath.Dir(f.file)] = struct{}{}
+		}
+	}
+	j.commit()
+
+	if err = r.copyDirModes(files, r.path); err != nil {
+		return err
+	}
+	removeEmptyDirs(r.path, oldDirs)
+	return nil
+}
+
+// 把临时文件移动到目标位置，只替换了文件名或文件夹名时移动原文件，备份时原文件移动为.bak文件
+func (r *replacerInfo) saveFileInPlace(f *replacedFile, j *journal) error {
+	newFilePath := joinFilePath(r.path, f.newFile)
+	if newFilePath != f.file {
+		if _, err := os.Lstat(newFilePath); err == nil {
+			return fmt.Errorf("rename %s to %s failed, file already exists", f.file, newFilePath)
+		}
+		if err := j.mkdirAll(filepath.Dir(newFilePath)); err != nil {
+			return err
+		}
+	}
+
+	if r.backup {
+		if f.tmpFile == "" { // 原文件移动为.bak文件，先复制一份
+			tmpFile, err := copyToTempFile(f.file, f.mode)
+			if err != nil {
+				return err
+			}
+			f.tmpFile = tmpFile
+		}
+		if err := j.moveFile(f.file, f.file+backupSuffix); err != nil {
+			return err
+		}
+	}
+
+	if f.tmpFile == "" {
+		return j.moveFile(f.file, newFilePath)
+	}
+	if err := j.moveFile(f.tmpFile, newFilePath); err != nil {
+		return err
+	}
+	if newFilePath != f.file && !r.backup { // 文件名或文件夹名被替换，删除原文件
+		return j.removeFile(f.file)
+	}
+	return nil
+}
+
+// 删除文件夹名被替换后留下的空目录，从最深的目录开始删除，不删除根目录
+func removeEmptyDirs(root string, dirs map[string]struct{}) {
+	all := make(map[string]struct{})
+	for dir := range dirs {
+		for ; len(dir) > len(root) && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+			all[dir] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(all))
+	for dir := range all {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, dir := range sorted {
+		_ = os.Remove(dir) // 目录不为空时删除失败，忽略
+	}
+}
+
+// 操作类型
+const (
+	journalCreateDir = iota + 1 // 创建了目录，回滚时删除
+	journalMoveFile             // 移动了文件，回滚时移回原位置
+	journalSetAside             // 被覆盖或删除的文件移动到了临时文件，回滚时恢复，提交时删除临时文件
+)
+
+type journalEntry struct {
+	kind int
+	path string // 创建的目录，或者文件移动后的路径
+	from string // 文件移动前的路径，或者保存原文件的临时文件
+}
+
+// journal 原地替换的操作记录，只记录路径，不保存文件内容，替换失败时按相反顺序回滚
+type journal struct {
+	entries []journalEntry
+}
+
+// 移动文件，目标文件已存在时先移动到临时文件
+func (j *journal) moveFile(src string, dst string) error {
+	if err := j.setAside(dst); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	j.entries = append(j.entries, journalEntry{kind: journalMoveFile, path: dst, from: src})
+	return nil
+}
+
+// 删除文件，先移动到临时文件，提交时才删除
+func (j *journal) removeFile(path string) error {
+	return j.setAside(path)
+}
+
+// 把文件移动到同目录下的临时文件，文件不存在时不处理
+func (j *journal) setAside(path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dir, filename := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, "."+filename+".orig*")
+	if err != nil {
+		return err
+	}
+	_ = tmp.Close()
+	if err = os.Rename(path, tmp.Name()); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	j.entries = append(j.entries, journalEntry{kind: journalSetAside, path: path, from: tmp.Name()})
+	return nil
+}
+
+// 创建目录，记录新建的每一级目录
+func (j *journal) mkdirAll(dir string) error {
+	var dirs []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		dirs = append(dirs, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Mkdir(dirs[i], 0755); err != nil {
+			return err
+		}
+		j.entries = append(j.entries, journalEntry{kind: journalCreateDir, path: dirs[i]})
+	}
+	return nil
+}
+
+// 按相反顺序撤销所有操作
+func (j *journal) rollback() error {
+	var errs []string
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		entry := j.entries[i]
+		var err error
+		switch entry.kind {
+		case journalCreateDir:
+			err = os.Remove(entry.path)
+		case journalMoveFile:
+			err = os.Rename(entry.path, entry.from)
+		case journalSetAside:
+			err = os.Rename(entry.from, entry.path)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+		}
+	}
+	j.entries = nil
+
+	if len(errs) > 0 {
+		return errors.New("rollback failed: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// 所有文件保存成功后删除移走的原文件
+func (j *journal) commit() {
+	for _, entry := range j.entries {
+		if entry.kind == journalSetAside {
+			_ = os.Remove(entry.from)
+		}
+	}
+	j.entries = nil
+}
+
+// 把src的内容写入path所在目录的临时文件，权限和原文件相同，符号链接创建指向src内容的临时链接，返回临时文件路径
+func writeTempFile(path string, src io.Reader, mode os.FileMode) (string, error) {
+	dir, filename := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, "."+filename+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+
+	if mode&os.ModeSymlink != 0 {
+		_ = tmp.Close()
+		var target []byte
+		target, err = io.ReadAll(src)
+		if err == nil {
+			err = os.Remove(tmpName)
+		}
+		if err == nil {
+			err = os.Symlink(string(target), tmpName)
+		}
+	} else {
+		_, err = io.Copy(tmp, src)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(tmpName, mode.Perm())
+		}
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return "", err
+	}
+	return tmpName, nil
+}
+
+// 流式复制文件到同目录下的临时文件，符号链接复制链接目标
+func copyToTempFile(path string, mode os.FileMode) (string, error) {
+	if mode&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return writeTempFile(path, strings.NewReader(target), mode)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close() //nolint
+	return writeTempFile(path, src, mode)
+}
@@ -0,0 +1,135 @@
+package replacer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 替换字段的匹配器
+type fieldMatcher struct {
+	field Field
+	re    *regexp.Regexp // IsRegexp为true时使用
+}
+
+// 匹配位置
+type match struct {
+	start, end int
+	field      int    // 替换字段的序号
+	newValue   []byte // 替换后的内容
+}
+
+func compileFields(fields []Field) ([]*fieldMatcher, error) {
+	matchers := make([]*fieldMatcher, 0, len(fields))
+	for _, field := range fields {
+		m := &fieldMatcher{field: field}
+		if field.IsRegexp {
+			re, err := regexp.Compile(field.Old)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp '%s': %v", field.Old, err)
+			}
+			m.re = re
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// 找出字段所有可以替换的位置，位置之间可能重叠
+func (m *fieldMatcher) findAll(data []byte, field int) []match {
+	var matches []match
+	if m.re != nil {
+		for _, loc := range m.re.FindAllSubmatchIndex(data, -1) {
+			if loc[0] == loc[1] || !m.isWholeWord(data, loc[0], loc[1]) {
+				continue // 忽略空匹配
+			}
+			newValue := m.re.Expand(nil, []byte(m.field.New), data, loc)
+			matches = append(matches, match{start: loc[0], end: loc[1], field: field, newValue: newValue})
+		}
+		return matches
+	}
+
+	old := []byte(m.field.Old)
+	if len(old) == 0 {
+		return nil
+	}
+	for offset := 0; offset < len(data); {
+		i := bytes.Index(data[offset:], old)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		if m.isWholeWord(data, start, start+len(old)) {
+			matches = append(matches, match{start: start, end: start + len(old), field: field, newValue: []byte(m.field.New)})
+		}
+		offset = start + 1
+	}
+	return matches
+}
+
+// 判断匹配内容前后是否为标识符边界
+func (m *fieldMatcher) isWholeWord(data []byte, start int, end int) bool {
+	if !m.field.IsWholeWord {
+		return true
+	}
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRune(data[:start]); isIdentRune(r) {
+			return false
+		}
+	}
+	if end < len(data) {
+		if r, _ := utf8.DecodeRune(data[end:]); isIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// 一次替换所有字段，替换后的内容不会再被其他字段替换，多个字段在同一位置匹配时使用最长的匹配，
+// 长度相同时使用靠前的字段，返回替换后的内容和每个字段的替换次数
+func replaceAll(data []byte, matchers []*fieldMatcher) ([]byte, []int) {
+	counts := make([]int, len(matchers))
+	var matches []match
+	for i, m := range matchers {
+		matches = append(matches, m.findAll(data, i)...)
+	}
+	if len(matches) == 0 {
+		return data, counts
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		if matches[i].end != matches[j].end {
+			return matches[i].end > matches[j].end
+		}
+		return matches[i].field < matches[j].field
+	})
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	pos := 0
+	for _, mt := range matches {
+		if mt.start < pos {
+			continue // 和已替换的内容重叠
+		}
+		buf.Write(data[pos:mt.start])
+		buf.Write(mt.newValue)
+		pos = mt.end
+		counts[mt.field]++
+	}
+	buf.Write(data[pos:])
+	return buf.Bytes(), counts
+}
+
+func replaceString(s string, matchers []*fieldMatcher) string {
+	data, _ := replaceAll([]byte(s), matchers)
+	return string(data)
+}
@@ -0,0 +1,234 @@
+package replacer
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// proto文件的go_package选项，例如 option go_package = "github.com/foo/bar/api/v1;v1";
+var goPackageRegexp = regexp.MustCompile(`(option\s+go_package\s*=\s*")([^"]*)(")`)
+
+// SetModulePath 设置替换模块路径，包括go.mod的module、require、replace和exclude路径、go文件的import路径和proto文件的go_package选项，
+// 在替换字段之前执行，替换后解析每个go文件，有语法错误时返回错误并且不保存该文件，在源目录替换时不修改任何文件
+func (r *replacerInfo) SetModulePath(oldPath string, newPath string) {
+	r.oldModulePath = strings.TrimSuffix(oldPath, "/")
+	r.newModulePath = strings.TrimSuffix(newPath, "/")
+}
+
+// 替换模块路径，返回替换后的内容和替换次数
+func (r *replacerInfo) rewriteModulePath(file string, data []byte) ([]byte, int) {
+	if r.oldModulePath == "" || r.oldModulePath == r.newModulePath {
+		return data, 0
+	}
+
+	switch {
+	case filepath.Base(file) == "go.mod":
+		return rewriteGoMod(data, r.oldModulePath, r.newModulePath)
+	case isGoFile(file):
+		return rewriteGoImports(data, r.oldModulePath, r.newModulePath)
+	case strings.HasSuffix(file, ".proto"):
+		count := 0
+		data = goPackageRegexp.ReplaceAllFunc(data, func(option []byte) []byte {
+			sub := goPackageRegexp.FindSubmatch(option)
+			newPath, ok := rewritePath(string(sub[2]), r.oldModulePath, r.newModulePath)
+			if !ok {
+				return option
+			}
+			count++
+			return []byte(string(sub[1]) + newPath + string(sub[3]))
+		})
+		return data, count
+	}
+	return data, 0
+}
+
+// 替换以模块路径开头的路径，例如github.com/foo/bar/api/v1;v1
+func rewritePath(path string, oldPath string, newPath string) (string, bool) {
+	if path == oldPath {
+		return newPath, true
+	}
+	for _, sep := range []string{"/", ";"} {
+		if strings.HasPrefix(path, oldPath+sep) {
+			return newPath + path[len(oldPath):], true
+		}
+	}
+	return path, false
+}
+
+// 替换go.mod中module、require、exclude和replace指令的模块路径，包括子模块路径，
+// replace指令替换=>两边的模块路径，本地路径不变，带引号的路径保留引号
+func rewriteGoMod(data []byte, oldPath string, newPath string) ([]byte, int) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	count := 0
+	block := "" // 所在的块的指令，例如require ( ... )中的require
+	for i, line := range lines {
+		tokens := goModTokens(line)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		directive, args := block, tokens
+		if block == "" {
+			directive, args = string(tokens[0].text), tokens[1:]
+			if len(args) == 1 && string(args[0].text) == "(" {
+				block = directive
+				continue
+			}
+		} else if string(tokens[0].text) == ")" {
+			block = ""
+			continue
+		}
+
+		var paths []goModToken
+		switch directive {
+		case "module", "require", "exclude":
+			if len(args) > 0 {
+				paths = append(paths, args[0])
+			}
+		case "replace":
+			for j, arg := range args {
+				if j == 0 || string(args[j-1].text) == "=>" {
+					paths = append(paths, arg)
+				}
+			}
+		}
+
+		// 从后往前替换，前面的位置不受影响
+		for j := len(paths) - 1; j >= 0; j-- {
+			if newToken, ok := rewriteGoModPath(paths[j].text, oldPath, newPath); ok {
+				line = append(line[:paths[j].start:paths[j].start], append([]byte(newToken), line[paths[j].end:]...)...)
+				count++
+			}
+		}
+		lines[i] = line
+	}
+	if count == 0 {
+		return data, 0
+	}
+	return bytes.Join(lines, nil), count
+}
+
+type goModToken struct {
+	start, end int
+	text       []byte
+}
+
+// 拆分go.mod的一行，引号内的内容作为一个token，括号单独作为token，忽略//注释
+func goModTokens(line []byte) []goModToken {
+	var tokens []goModToken
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return tokens
+		}
+
+		start := i
+		switch c {
+		case '(', ')':
+			i++
+		case '"', '`':
+			for i++; i < len(line) && line[i] != c; i++ {
+				if c == '"' && line[i] == '\\' {
+					i++
+				}
+			}
+			if i < len(line) {
+				i++
+			}
+		default:
+			for ; i < len(line) && !strings.ContainsRune(" \t\r\n()", rune(line[i])); i++ {
+			}
+		}
+		if i > len(line) {
+			i = len(line)
+		}
+		tokens = append(tokens, goModToken{start: start, end: i, text: line[start:i]})
+	}
+	return tokens
+}
+
+// 替换go.mod中的一个模块路径，带引号的路径替换引号内的路径
+func rewriteGoModPath(token []byte, oldPath string, newPath string) (string, bool) {
+	path := string(token)
+	quoted := len(path) >= 2 && (path[0] == '"' || path[0] == '`')
+	if quoted {
+		unquoted, err := strconv.Unquote(path)
+		if err != nil {
+			return "", false
+		}
+		path = unquoted
+	}
+
+	path, ok := rewritePath(path, oldPath, newPath)
+	if !ok {
+		return "", false
+	}
+	if !quoted {
+		return path, true
+	}
+	if token[0] == '`' {
+		return "`" + path + "`", true
+	}
+	return strconv.Quote(path), true
+}
+
+// 替换go文件的import路径，只修改路径字符串，不改变代码格式
+func rewriteGoImports(data []byte, oldPath string, newPath string) ([]byte, int) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", data, parser.ImportsOnly)
+	if err != nil {
+		return data, 0 // 保存前会检查go文件语法
+	}
+
+	type edit struct {
+		start, end int
+		path       string
+	}
+	var edits []edit
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if newImport, ok := rewritePath(path, oldPath, newPath); ok {
+			edits = append(edits, edit{
+				start: fset.Position(spec.Path.Pos()).Offset,
+				end:   fset.Position(spec.Path.End()).Offset,
+				path:  strconv.Quote(newImport),
+			})
+		}
+	}
+	if len(edits) == 0 {
+		return data, 0
+	}
+
+	// 从后往前替换，前面的位置不受影响
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	newData := append([]byte{}, data...)
+	for _, e := range edits {
+		newData = append(newData[:e.start], append([]byte(e.path), newData[e.end:]...)...)
+	}
+	return newData, len(edits)
+}
+
+// 检查替换后的go文件语法
+func validateGoFile(f *replacedFile) error {
+	if f.isSymlink() || f.isBinary {
+		return nil
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", f.data, parser.AllErrors); err != nil {
+		return fmt.Errorf("invalid go file after rewriting module path, %v", err)
+	}
+	return nil
+}
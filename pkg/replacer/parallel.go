@@ -0,0 +1,79 @@
+package replacer
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// FileError 处理单个文件的错误
+type FileError struct {
+	File string // 文件相对路径
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors 多个文件的错误，按文件顺序排列，一个文件失败不会影响其他文件的处理
+type FileErrors []*FileError
+
+func (e FileErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// SetWorkers 设置同时处理文件的goroutine数量，小于等于0时使用CPU核数
+func (r *replacerInfo) SetWorkers(workers int) {
+	r.workers = workers
+}
+
+// SetProgress 设置进度回调，每处理完一个文件调用一次，done是已处理的文件数，total是需要处理的文件总数，不包括忽略的文件
+func (r *replacerInfo) SetProgress(fn func(done int, total int)) {
+	r.progress = fn
+}
+
+// 使用固定数量的goroutine处理文件，所有文件处理完成后返回，错误按文件顺序排列
+func (r *replacerInfo) forEachFile(files []string, fn func(i int, file string) error) error {
+	workers := r.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(files))
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers && w < len(files); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fn(i, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var fileErrs FileErrors
+	for i, err := range errs {
+		if err != nil {
+			fileErrs = append(fileErrs, &FileError{File: r.relPath(files[i]), Err: err})
+		}
+	}
+	if len(fileErrs) > 0 {
+		return fileErrs
+	}
+	return nil
+}
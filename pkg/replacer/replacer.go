@@ -1,13 +1,14 @@
 package replacer
 
 import (
-	"bytes"
 	"embed"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/zhufuyi/gotool/pkg/gofile"
@@ -15,29 +16,51 @@ import (
 
 var _ Replacer = (*replacerInfo)(nil)
 
+// 替换内容的文本文件的最大字节数，超过时和二进制文件一样原样复制，限制每个goroutine占用的内存
+const maxTextFileSize = 64 << 20
+
 // Replacer 接口
 type Replacer interface {
 	SetReplacementFields(fields []Field)
 	SetIgnoreFiles(filenames ...string)
 	SetIgnoreSubDirs(dirs ...string)
+	LoadIgnoreFiles(filenames ...string) error
 	SetSubDirs(subDirs ...string)
 	SetOutDir(absDir string, name ...string) error
+	SetInPlace(backup bool)
+	SetGoAware(goAware bool)
+	SetReplaceBinary(replaceBinary bool)
+	SetWorkers(workers int)
+	SetProgress(fn func(done int, total int))
+	SetModulePath(oldPath string, newPath string)
 	GetBasePath() string
+	Report() *Report
 	GetOutPath() string
 	SaveFiles() error
+	DryRun() ([]FileChange, error)
 	ReadFile(filename string) ([]byte, error)
 }
 
 // replacerInfo replace设置信息
 type replacerInfo struct {
-	path              string   // 模板目录路径(不包含.或..)
-	fs                embed.FS // 模板目录对应二进制对象
-	isActual          bool     // fs字段是否来源实际路径，如果为true，使用io操作文件，如果为false使用fs操作文件
-	files             []string // 模板文件列表
-	ignoreFiles       []string // 忽略替换的文件列表
-	ignoreDirs        []string // 忽略处理的子目录
-	replacementFields []Field  // 从模板文件转为新文件需要替换的字符
-	outPath           string   // 输出替换后文件存放目录路径
+	path              string                    // 模板目录路径(不包含.或..)
+	fs                embed.FS                  // 模板目录对应二进制对象
+	isActual          bool                      // fs字段是否来源实际路径，如果为true，使用io操作文件，如果为false使用fs操作文件
+	files             []string                  // 模板文件列表
+	ignoreFiles       []ignoreRule              // 忽略替换的文件
+	ignoreDirs        []ignoreRule              // 忽略处理的子目录
+	ignoreRules       []ignoreRule              // 从.gitignore等文件读取的忽略规则
+	replacementFields []Field                   // 从模板文件转为新文件需要替换的字符
+	outPath           string                    // 输出替换后文件存放目录路径
+	inPlace           bool                      // 是否在源目录替换
+	backup            bool                      // 在源目录替换时是否保留.bak备份文件
+	goAware           bool                      // go文件是否只替换标识符、import路径和包名
+	oldModulePath     string                    // 模板的模块路径
+	newModulePath     string                    // 新的模块路径
+	replaceBinary     bool                      // 是否替换二进制文件的内容
+	workers           int                       // 同时处理文件的goroutine数量
+	progress          func(done int, total int) // 进度回调
+	report            *Report                   // 最近一次替换的报告
 }
 
 // New 根据指定路径创建replacer
@@ -77,23 +100,25 @@ type Field struct {
 	Old             string // 模板字段
 	New             string // 新字段
 	IsCaseSensitive bool   // 第一个字母是否区分大小写
+	IsRegexp        bool   // Old是否为正则表达式，New可以使用$1、${name}引用分组
+	IsWholeWord     bool   // 是否只替换完整的单词或标识符，例如Old为user时不替换userName
+	IsAllStyles     bool   // 是否替换所有命名风格和复数形式，例如user_order同时替换userOrder、UserOrder、user-order、USER_ORDER、user order、userOrders等
 }
 
-// SetReplacementFields 设置替换字段，注：old字符尽量不要存在包含关系，如果存在，在设置Field时注意先后顺序
+// SetReplacementFields 设置替换字段，所有字段一次替换，替换后的内容不会再被其他字段替换，
+// 多个字段在同一位置匹配时使用最长的匹配，长度相同时使用靠前的字段
 func (r *replacerInfo) SetReplacementFields(fields []Field) {
 	var newFields []Field
 	for _, field := range fields {
-		if field.IsCaseSensitive && isFirstAlphabet(field.Old) { // 拆分首字母大小写两个字段
-			newFields = append(newFields,
-				Field{ // 把第一个字母转为大写
-					Old: strings.ToUpper(field.Old[:1]) + field.Old[1:],
-					New: strings.ToUpper(field.New[:1]) + field.New[1:],
-				},
-				Field{ // 把第一个字母转为小写
-					Old: strings.ToLower(field.Old[:1]) + field.Old[1:],
-					New: strings.ToLower(field.New[:1]) + field.New[1:],
-				},
-			)
+		if field.IsAllStyles && !field.IsRegexp {
+			newFields = append(newFields, expandStyles(field)...)
+		} else if field.IsCaseSensitive && !field.IsRegexp && isFirstAlphabet(field.Old) && field.New != "" { // 拆分首字母大小写两个字段
+			upper, lower := field, field
+			upper.Old = strings.ToUpper(field.Old[:1]) + field.Old[1:] // 把第一个字母转为大写
+			upper.New = strings.ToUpper(field.New[:1]) + field.New[1:]
+			lower.Old = strings.ToLower(field.Old[:1]) + field.Old[1:] // 把第一个字母转为小写
+			lower.New = strings.ToLower(field.New[:1]) + field.New[1:]
+			newFields = append(newFields, upper, lower)
 		} else {
 			newFields = append(newFields, field)
 		}
@@ -130,14 +155,25 @@ func (r *replacerInfo) SetSubDirs(subDirs ...string) {
 	r.files = files
 }
 
-// SetIgnoreFiles 设置忽略处理的文件
+// SetIgnoreFiles 设置忽略处理的文件，支持文件名和glob，例如go.sum、*.pb.go、**/*.pb.go、docs/**，
+// 包含/的glob匹配相对于源目录的路径
 func (r *replacerInfo) SetIgnoreFiles(filenames ...string) {
-	r.ignoreFiles = append(r.ignoreFiles, filenames...)
+	for _, filename := range filenames {
+		if rule, ok := newIgnoreRule(filepath.ToSlash(filename)); ok && !rule.negate {
+			rule.dirOnly = false
+			r.ignoreFiles = append(r.ignoreFiles, rule)
+		}
+	}
 }
 
-// SetIgnoreSubDirs 设置忽略处理的子目录
+// SetIgnoreSubDirs 设置忽略处理的子目录，支持目录名和glob，例如vendor、internal/model、**/testdata，
+// 目录名只匹配完整的目录名，例如vendor不匹配myvendorlib
 func (r *replacerInfo) SetIgnoreSubDirs(dirs ...string) {
-	r.ignoreDirs = append(r.ignoreDirs, dirs...)
+	for _, dir := range dirs {
+		if rule, ok := newIgnoreRule(filepath.ToSlash(dir)); ok && !rule.negate {
+			r.ignoreDirs = append(r.ignoreDirs, rule)
+		}
+	}
 }
 
 // SetOutDir 设置输出目录，优先使用absPath绝对路径，如果absPath为空，自动在当前目录根据参数name和时间生成绝对路径
@@ -161,8 +197,11 @@ func (r *replacerInfo) SetOutDir(absPath string, name ...string) error {
 	return nil
 }
 
-// GetOutPath 获取输出目录路径
+// GetOutPath 获取输出目录路径，在源目录替换时是源目录路径
 func (r *replacerInfo) GetOutPath() string {
+	if r.inPlace {
+		return r.path
+	}
 	return r.outPath
 }
 
@@ -189,123 +228,338 @@ func (r *replacerInfo) ReadFile(filename string) ([]byte, error) {
 	return r.fs.ReadFile(foundFile[0])
 }
 
-// SaveFiles 导出文件
+// SaveFiles 导出文件，如果设置了在源目录替换，直接修改源目录下的文件
 func (r *replacerInfo) SaveFiles() error {
+	if r.inPlace {
+		return r.saveInPlace()
+	}
+
 	if r.outPath == "" {
 		r.outPath = gofile.GetRunPath() + gofile.GetPathDelimiter() + "template_" + time.Now().Format("0102150405")
 	}
 
-	for _, file := range r.files {
-		if r.isInIgnoreDir(file) || r.isIgnoreFile(file) {
-			continue
-		}
+	// 文件在多个goroutine中并发保存，只保留路径用来设置目录权限，不保留文件内容
+	var mu sync.Mutex
+	var files []*replacedFile
+	err := r.replaceFiles(func(f *replacedFile) error {
+		mu.Lock()
+		files = append(files, &replacedFile{oldFile: f.oldFile, newFile: f.newFile})
+		mu.Unlock()
 
-		// 从二进制读取模板文件内容使用embed.FS，如果要从指定目录读取使用os.ReadFile
-		var data []byte
-		var err error
-		if r.isActual {
-			data, err = os.ReadFile(file)
-		} else {
-			data, err = r.fs.ReadFile(file)
+		// 保存文件
+		if f.copyOnly {
+			return r.copyToNewFile(f.file, r.getNewFilePath(f.newFile), f.mode)
 		}
+		return saveToNewFile(r.getNewFilePath(f.newFile), f.data, f.mode)
+	})
+	if dirErr := r.copyDirModes(files, r.outPath); err == nil {
+		err = dirErr
+	}
+	return err
+}
+
+// 替换后的文件信息
+type replacedFile struct {
+	index    int         // 文件序号，用来按文件顺序排列结果
+	file     string      // 原文件路径
+	oldFile  string      // 原文件相对路径，例如/dir/file.go
+	newFile  string      // 替换文件名和文件夹名后的相对路径
+	oldData  []byte      // 原文件内容
+	data     []byte      // 替换后文件内容
+	counts   []int       // 每个替换字段的替换次数，和replacementFields一一对应
+	modules  int         // 模块路径的替换次数
+	mode     os.FileMode // 原文件类型和权限
+	isBinary bool        // 是否二进制文件
+	copyOnly bool        // 二进制文件或超过maxTextFileSize的文件原样复制，没有读取内容，oldData和data为空
+	skipped  string      // 原样复制的原因，SkipBinary或SkipTooLarge
+	tmpFile  string      // 在源目录替换时保存替换后内容的临时文件
+}
+
+// 是否符号链接，符号链接的内容是链接目标
+func (f *replacedFile) isSymlink() bool {
+	return f.mode&os.ModeSymlink != 0
+}
+
+// 读取文件内容和权限，符号链接读取链接目标，不读取指向的文件，
+// 原样复制的二进制文件只读取开头用来判断文件类型，超过maxTextFileSize的文件不读取，保存时再流式复制
+func (r *replacerInfo) readSourceFile(f *replacedFile) error {
+	f.mode = 0644 // 嵌入的文件权限是只读，使用默认权限
+	if r.isActual {
+		info, err := os.Lstat(f.file)
 		if err != nil {
 			return err
 		}
-
-		// 替换文本内容
-		for _, field := range r.replacementFields {
-			data = bytes.ReplaceAll(data, []byte(field.Old), []byte(field.New))
+		f.mode = info.Mode()
+		if f.isSymlink() {
+			target, err := os.Readlink(f.file)
+			f.oldData = []byte(target)
+			return err
 		}
+		if info.Size() > maxTextFileSize {
+			f.copyOnly, f.skipped = true, SkipTooLarge
+			return nil
+		}
+	}
+
+	file, err := r.openSourceFile(f.file)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint
+
+	head := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+	f.isBinary = isBinary(head)
+	if f.isBinary && !r.replaceBinary {
+		f.copyOnly, f.skipped = true, SkipBinary
+		return nil
+	}
+
+	rest, err := io.ReadAll(io.LimitReader(file, maxTextFileSize-int64(n)+1))
+	if err != nil {
+		return err
+	}
+	if int64(n+len(rest)) > maxTextFileSize { // 嵌入的文件
+		f.copyOnly, f.skipped = true, SkipTooLarge
+		return nil
+	}
+	f.oldData = append(head, rest...)
+	return nil
+}
+
+// 从二进制读取模板文件使用embed.FS，如果要从指定目录读取使用os.Open
+func (r *replacerInfo) openSourceFile(file string) (io.ReadCloser, error) {
+	if r.isActual {
+		return os.Open(file)
+	}
+	return r.fs.Open(file)
+}
+
+// 替换所有文件的内容、文件名和文件夹名，每个文件替换后调用fn，多个文件并发处理，fn在多个goroutine中调用，
+// 一个文件失败时继续处理其他文件，返回按文件顺序排列的FileErrors，结束后生成替换报告
+func (r *replacerInfo) replaceFiles(fn func(f *replacedFile) error) error {
+	r.report = nil
+	matchers, err := compileFields(r.replacementFields)
+	if err != nil {
+		return err
+	}
 
-		// 获取新文件路径
-		newFilePath := r.getNewFilePath(file)
-		dir, filename := filepath.Split(newFilePath)
-		// 替换文件名和文件夹名
-		for _, field := range r.replacementFields {
-			if strings.Contains(dir, field.Old) {
-				dir = strings.ReplaceAll(dir, field.Old, field.New)
+	reports := make([]*FileReport, len(r.files)) // 和r.files一一对应
+	ignoredDirs := make(map[string]bool)
+	var files []string
+	var positions []int // 处理的文件在r.files中的位置
+	for k, file := range r.files {
+		reason, path := r.ignoreReason(file)
+		if reason == "" {
+			files = append(files, file)
+			positions = append(positions, k)
+			continue
+		}
+		if strings.HasSuffix(path, "/") { // 忽略的目录只记录一次
+			if ignoredDirs[path] {
+				continue
 			}
-			if strings.Contains(filename, field.Old) {
-				filename = strings.ReplaceAll(filename, field.Old, field.New)
+			ignoredDirs[path] = true
+		}
+		reports[k] = &FileReport{Path: path, Skipped: reason}
+	}
+
+	var mu sync.Mutex
+	modules, counts := 0, make([]int, len(matchers))
+	defer func() {
+		r.report = r.newReport(reports, modules, counts)
+	}()
+
+	done := 0
+	return r.forEachFile(files, func(i int, file string) error {
+		report := &FileReport{Path: r.relPath(file)}
+		f, err := r.replaceFile(file, matchers)
+		if err == nil {
+			f.index = i
+			err = fn(f)
+		}
+		if err != nil {
+			report.Error = err.Error()
+		} else {
+			if f.newFile != f.oldFile {
+				report.NewPath = strings.TrimPrefix(filepath.ToSlash(f.newFile), "/")
 			}
+			report.Skipped = f.skipped
+			report.Replacements = r.fileReplacements(f)
+		}
 
-			if newFilePath != dir+filename {
-				newFilePath = dir + filename
+		mu.Lock()
+		defer mu.Unlock()
+		reports[positions[i]] = report
+		if err == nil {
+			modules += f.modules
+			for k, n := range f.counts {
+				counts[k] += n
 			}
+		}
+		done++
+		if r.progress != nil {
+			r.progress(done, len(files))
+		}
+		return err
+	})
+}
+
+// 替换一个文件的内容、文件名和文件夹名
+func (r *replacerInfo) replaceFile(file string, matchers []*fieldMatcher) (*replacedFile, error) {
+	f := &replacedFile{
+		file:    file,
+		oldFile: strings.Replace(file, r.path, "", 1),
+	}
+	if err := r.readSourceFile(f); err != nil {
+		return nil, err
+	}
 
-			//tmp := strings.ReplaceAll(dir, field.Old, field.New) + strings.ReplaceAll(filename, field.Old, field.New)
-			//if newFilePath != tmp {
-			//	newFilePath = tmp
-			//	break
-			//}
+	data := f.oldData
+	switch {
+	case f.isSymlink(): // 符号链接只替换相对路径的链接目标
+		f.data, f.counts = data, make([]int, len(matchers))
+		if !filepath.IsAbs(string(data)) {
+			f.data, f.counts = replaceAll(data, matchers)
 		}
 
-		// 保存文件
-		err = saveToNewFile(newFilePath, data)
-		if err != nil {
-			return err
+	case f.copyOnly: // 二进制文件和过大的文件原样复制
+		f.counts = make([]int, len(matchers))
+
+	default:
+		// 先替换模块路径
+		data, f.modules = r.rewriteModulePath(file, data)
+
+		// 替换文本内容，go文件解析失败时按文本替换
+		if r.goAware && isGoFile(file) {
+			f.data, f.counts, _ = replaceGoCode(data, matchers)
+		}
+		if f.data == nil {
+			f.data, f.counts = replaceAll(data, matchers)
+		}
+
+		// 替换模块路径后检查go文件的语法，有错误时不调用fn
+		if r.newModulePath != "" && isGoFile(file) {
+			if err := validateGoFile(f); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return nil
+	// 替换文件名和文件夹名
+	dir, filename := filepath.Split(f.oldFile)
+	f.newFile = replaceString(dir, matchers) + replaceString(filename, matchers)
+
+	return f, nil
 }
 
-func (r *replacerInfo) isIgnoreFile(file string) bool {
-	isIgnore := false
-	_, filename := filepath.Split(file)
-	for _, v := range r.ignoreFiles {
-		if filename == v {
-			isIgnore = true
-			break
-		}
-	}
-	return isIgnore
+// 根据替换后的相对路径获取输出文件路径
+func (r *replacerInfo) getNewFilePath(newFile string) string {
+	return joinFilePath(r.outPath, newFile)
 }
 
-func (r *replacerInfo) isInIgnoreDir(file string) bool {
-	isIgnore := false
-	dir, _ := filepath.Split(file)
-	for _, v := range r.ignoreDirs {
-		if strings.Contains(dir, v) {
-			isIgnore = true
-			break
-		}
+func joinFilePath(root string, relPath string) string {
+	filePath := root + relPath
+	if runtime.GOOS == "windows" {
+		filePath = strings.ReplaceAll(filePath, "/", "\\")
 	}
-	return isIgnore
+
+	return filePath
 }
 
-func (r *replacerInfo) getNewFilePath(file string) string {
-	var newFilePath string
-	if r.isActual {
-		newFilePath = r.outPath + strings.Replace(file, r.path, "", 1)
-	} else {
-		newFilePath = r.outPath + strings.Replace(file, r.path, "", 1)
+// 保存文件，权限和原文件相同，符号链接保存为链接
+func saveToNewFile(filePath string, data []byte, mode os.FileMode) error {
+	// 创建目录，目录权限在所有文件保存后设置
+	dir, _ := filepath.Split(filePath)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
 	}
 
-	if runtime.GOOS == "windows" {
-		newFilePath = strings.ReplaceAll(newFilePath, "/", "\\")
+	if mode&os.ModeSymlink != 0 {
+		if err = os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(string(data), filePath)
+	}
+
+	// 保存文件
+	err = os.WriteFile(filePath, data, mode.Perm())
+	if err != nil {
+		return err
 	}
 
-	return newFilePath
+	return os.Chmod(filePath, mode.Perm()) // 不受umask影响
 }
 
-func saveToNewFile(filePath string, data []byte) error {
-	// 创建目录
+// 流式复制文件，权限和原文件相同
+func (r *replacerInfo) copyToNewFile(srcFile string, filePath string, mode os.FileMode) error {
 	dir, _ := filepath.Split(filePath)
-	err := os.MkdirAll(dir, 0666)
+	err := os.MkdirAll(dir, 0755)
 	if err != nil {
 		return err
 	}
 
-	// 保存文件
-	err = os.WriteFile(filePath, data, 0666)
+	src, err := r.openSourceFile(srcFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint
+
+	dst, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return err
 	}
 
+	return os.Chmod(filePath, mode.Perm()) // 不受umask影响
+}
+
+// 设置新目录的权限和源目录相同，在所有文件保存后设置，避免只读目录无法写入文件
+func (r *replacerInfo) copyDirModes(files []*replacedFile, root string) error {
+	if !r.isActual {
+		return nil
+	}
+
+	done := make(map[string]bool)
+	for _, f := range files {
+		oldDir, newDir := filepath.Dir(f.oldFile), filepath.Dir(f.newFile)
+		for !isRootDir(oldDir) && !isRootDir(newDir) {
+			src, dst := joinFilePath(r.path, oldDir), joinFilePath(root, newDir)
+			if done[dst] {
+				break
+			}
+			done[dst] = true
+
+			if src != dst {
+				info, err := os.Stat(src)
+				if err != nil {
+					return err
+				}
+				if err = os.Chmod(dst, info.Mode().Perm()); err != nil {
+					return err
+				}
+			}
+			oldDir, newDir = filepath.Dir(oldDir), filepath.Dir(newDir)
+		}
+	}
 	return nil
 }
 
+func isRootDir(dir string) bool {
+	return dir == "." || dir == "/" || dir == string(filepath.Separator)
+}
+
 // 遍历嵌入的目录下所有文件，返回文件的绝对路径
 func listFiles(path string, fs embed.FS) ([]string, error) {
 	files := []string{}
@@ -2,6 +2,10 @@ package replacer
 
 import (
 	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -78,3 +82,804 @@ func TestNewWithFS(t *testing.T) {
 		})
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	r, err := New("testDir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{
+		{Old: "1234", New: "...."},
+		{Old: "test", New: "demo"},
+		{Old: "abcdef", New: "hello_", IsCaseSensitive: true},
+	})
+	changes, err := r.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want 1", len(changes))
+	}
+
+	change := changes[0]
+	if change.OldPath != "test.txt" || change.NewPath != "demo.txt" {
+		t.Errorf("got path %s -> %s", change.OldPath, change.NewPath)
+	}
+	if change.Count() != 2 || len(change.Replacements) != 2 {
+		t.Errorf("got replacements %+v", change.Replacements)
+	}
+	wantDiff := `diff --git a/test.txt b/demo.txt
+rename from test.txt
+rename to demo.txt
+--- a/test.txt
++++ b/demo.txt
+@@ -1,3 +1,3 @@
+-1234567890
+-abcdefghijklmnopqrstuvwxyz
++....567890
++hello_ghijklmnopqrstuvwxyz
+ ABCDEFGHIJKLMNOPQRSTUVWXYZ
+`
+	if change.Diff != wantDiff {
+		t.Errorf("got diff:\n%s\nwant:\n%s", change.Diff, wantDiff)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+	newText := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	want := `diff --git a/f b/f
+--- a/f
++++ b/f
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,4 +8,5 @@
+ h
+ i
+ j
+-k
+\ No newline at end of file
++k
++l
+`
+	if got := unifiedDiff("f", "f", oldText, newText); got != want {
+		t.Errorf("got diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	// 编辑序列的旧行和新行分别和原文相同
+	check := func(a []string, b []string, ops []diffOp) {
+		var oldLines, newLines []string
+		for _, op := range ops {
+			if op.kind != opInsert {
+				oldLines = append(oldLines, op.line)
+			}
+			if op.kind != opDelete {
+				newLines = append(newLines, op.line)
+			}
+		}
+		if strings.Join(oldLines, "") != strings.Join(a, "") || strings.Join(newLines, "") != strings.Join(b, "") {
+			t.Errorf("the ops do not transform a into b")
+		}
+	}
+
+	var a, b []string
+	for i := 0; i < 300; i++ {
+		a = append(a, fmt.Sprintf("line %d\n", i))
+		if i%3 != 0 {
+			b = append(b, fmt.Sprintf("line %d\n", i))
+		}
+		if i%5 == 0 {
+			b = append(b, fmt.Sprintf("new %d\n", i))
+		}
+	}
+	ops := diffLines(a, b)
+	check(a, b, ops)
+	edits := 0
+	for _, op := range ops {
+		if op.kind != opEqual {
+			edits++
+		}
+	}
+	if edits != 100+60 {
+		t.Errorf("got %d edits, want %d", edits, 160)
+	}
+
+	// 编辑距离超过diffMaxEditDistance时整体替换
+	a, b = nil, nil
+	for i := 0; i < diffMaxEditDistance; i++ {
+		a = append(a, fmt.Sprintf("a %d\n", i))
+		b = append(b, fmt.Sprintf("b %d\n", i))
+	}
+	a, b = append(a, "same\n"), append([]string{"same\n"}, b...)
+	ops = diffLines(a, b)
+	check(a, b, ops)
+	if len(ops) != len(a)+len(b) || ops[0].kind != opDelete || ops[len(ops)-1].kind != opInsert {
+		t.Errorf("want all lines deleted and inserted, got %d ops", len(ops))
+	}
+}
+
+func TestSaveFilesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"oldName/oldName.go": "package oldName\n",
+		"keep.txt":           "nothing to replace\n",
+	})
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "oldName", New: "newName"}})
+	r.SetInPlace(true)
+	if err = r.SaveFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if r.GetOutPath() != r.GetBasePath() {
+		t.Errorf("got out path %s, want %s", r.GetOutPath(), r.GetBasePath())
+	}
+	checkTestFiles(t, dir, map[string]string{
+		"newName/newName.go":     "package newName\n",
+		"oldName/oldName.go.bak": "package oldName\n",
+		"keep.txt":               "nothing to replace\n",
+	})
+	if _, err = os.Stat(filepath.Join(dir, "oldName/oldName.go")); !os.IsNotExist(err) {
+		t.Errorf("old file is not removed, err = %v", err)
+	}
+
+	// a.txt is renamed to b.txt that already exists, 0.txt that has been changed is rolled back
+	dir = t.TempDir()
+	files := map[string]string{
+		"0.txt": "a",
+		"a.txt": "a",
+		"b.txt": "b",
+	}
+	writeTestFiles(t, dir, files)
+	r, err = New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "a", New: "b"}})
+	r.SetInPlace(true)
+	if err = r.SaveFiles(); err == nil {
+		t.Fatal("expect error")
+	}
+	checkTestFiles(t, dir, files)
+	if entries, _ := os.ReadDir(dir); len(entries) != len(files) {
+		t.Errorf("got %d files after rollback, want %d", len(entries), len(files))
+	}
+}
+
+func TestSaveFilesInPlaceBackup(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00user"
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"user/user.txt":      "user",
+		"user/logo-user.png": png,
+		"keep.txt":           "user",
+	})
+	if err := os.Symlink("keep.txt", filepath.Join(dir, "user-link")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "user", New: "order"}})
+	r.SetInPlace(true)
+	if err = r.SaveFiles(); err != nil {
+		t.Fatal(err)
+	}
+	checkTestFiles(t, dir, map[string]string{
+		"order/order.txt":        "order",
+		"order/logo-order.png":   png,
+		"keep.txt":               "order",
+		"user/user.txt.bak":      "user",
+		"user/logo-user.png.bak": png,
+		"keep.txt.bak":           "user",
+	})
+	for _, link := range []string{"order-link", "user-link.bak"} {
+		if target, err := os.Readlink(filepath.Join(dir, link)); err != nil || target != "keep.txt" {
+			t.Errorf("%s got link target %q, %v", link, target, err)
+		}
+	}
+
+	// 没有留下临时文件
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasPrefix(info.Name(), ".") {
+			t.Errorf("temporary file %s is not removed", path)
+		}
+		return nil
+	})
+}
+
+func TestTooLargeFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"user.txt": "user", "user.log": ""})
+	if err := os.Truncate(filepath.Join(dir, "user.log"), maxTextFileSize+1); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "user", New: "order"}})
+	changes, err := r.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].NewPath != "order.log" || changes[0].Count() != 0 {
+		t.Errorf("got changes %+v", changes)
+	}
+	rp := r.Report()
+	if rp.Processed != 2 || rp.Skipped != 1 || rp.Files[0].Skipped != SkipTooLarge {
+		t.Errorf("got report %+v", rp)
+	}
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, want := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s got %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		data   string
+		want   string
+		counts []int
+	}{
+		{
+			name:   "simultaneous",
+			fields: []Field{{Old: "a", New: "b"}, {Old: "b", New: "a"}},
+			data:   "ab ba",
+			want:   "ba ab",
+			counts: []int{2, 2},
+		},
+		{
+			name:   "longest match",
+			fields: []Field{{Old: "user", New: "member"}, {Old: "userName", New: "nickname"}},
+			data:   "user.userName",
+			want:   "member.nickname",
+			counts: []int{1, 1},
+		},
+		{
+			name:   "whole word",
+			fields: []Field{{Old: "user", New: "account", IsWholeWord: true}},
+			data:   "user userName _user user.id 用户user",
+			want:   "account userName _user account.id 用户user",
+			counts: []int{2},
+		},
+		{
+			name:   "regexp",
+			fields: []Field{{Old: `get(\w+)ByID`, New: "find${1}ByKey", IsRegexp: true}, {Old: "ID", New: "Key"}},
+			data:   "getUserByID(ID)",
+			want:   "findUserByKey(Key)",
+			counts: []int{1, 1},
+		},
+		{
+			name:   "regexp whole word",
+			fields: []Field{{Old: `v\d+`, New: "v2", IsRegexp: true, IsWholeWord: true}},
+			data:   "v1 dev1 v10",
+			want:   "v2 dev1 v2",
+			counts: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matchers, err := compileFields(tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, counts := replaceAll([]byte(tt.data), matchers)
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			for i, count := range counts {
+				if count != tt.counts[i] {
+					t.Errorf("got counts %v, want %v", counts, tt.counts)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := compileFields([]Field{{Old: "(", IsRegexp: true}}); err == nil {
+		t.Error("expect invalid regexp error")
+	}
+}
+
+func TestExpandStyles(t *testing.T) {
+	r, err := New("testDir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "user_order", New: "customer_invoice", IsAllStyles: true}})
+	matchers, err := compileFields(r.(*replacerInfo).replacementFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := "user_order userOrder UserOrder user-order USER_ORDER user order userOrders USER_ORDERS user_order_id"
+	want := "customer_invoice customerInvoice CustomerInvoice customer-invoice CUSTOMER_INVOICE customer invoice customerInvoices CUSTOMER_INVOICES customer_invoice_id"
+	if got := replaceString(data, matchers); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	fields := expandStyles(Field{Old: "person", New: "user", IsAllStyles: true})
+	if len(fields) != 6 { // person, PERSON, Person, people, PEOPLE, People
+		t.Errorf("got %d fields %+v, want 6", len(fields), fields)
+	}
+}
+
+func TestReplaceGoCode(t *testing.T) {
+	code := `package user
+
+import "example.com/app/user/model"
+
+// user handler
+type userHandler struct {
+	userName string ` + "`json:\"userName\"`" + `
+}
+
+func (h *userHandler) get() string {
+	return "user: " + h.userName + model.Name
+}
+`
+	want := `package account
+
+import "example.com/app/account/model"
+
+// user handler
+type accountHandler struct {
+	accountName string ` + "`json:\"userName\"`" + `
+}
+
+func (h *accountHandler) get() string {
+	return "user: " + h.accountName + model.Name
+}
+`
+	matchers, err := compileFields([]Field{{Old: "user", New: "account"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, counts, err := replaceGoCode([]byte(code), matchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if counts[0] != 6 {
+		t.Errorf("got count %d, want 6", counts[0])
+	}
+
+	// 不替换的包中的名称保持不变
+	selectorCode := `package user
+
+import (
+	"example.com/app/user/model"
+	"example.com/other"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func get(x model.OldName) other.OldName {
+	var v other.Config
+	_ = yaml.OldName
+	return other.OldName(x.OldName) + model.OldName{}
+}
+`
+	selectorWant := `package account
+
+import (
+	"example.com/app/account/model"
+	"example.com/other"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func get(x model.NewName) other.OldName {
+	var v other.Config
+	_ = yaml.OldName
+	return other.OldName(x.NewName) + model.NewName{}
+}
+`
+	oldNameMatchers, err := compileFields([]Field{{Old: "user", New: "account"}, {Old: "OldName", New: "NewName", IsCaseSensitive: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err = replaceGoCode([]byte(selectorCode), oldNameMatchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != selectorWant {
+		t.Errorf("got:\n%s\nwant:\n%s", got, selectorWant)
+	}
+
+	// invalid go code is replaced as text
+	_, _, err = replaceGoCode([]byte("package user\nfunc {"), matchers)
+	if err == nil {
+		t.Error("expect parse error")
+	}
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.go": "package user\nfunc {", "b.go": code})
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "user", New: "account"}})
+	r.SetGoAware(true)
+	r.SetInPlace(false)
+	if err = r.SaveFiles(); err != nil {
+		t.Fatal(err)
+	}
+	checkTestFiles(t, dir, map[string]string{"a.go": "package account\nfunc {", "b.go": want})
+}
+
+func TestSetModulePath(t *testing.T) {
+	goMod := `module "github.com/foo/tpl"
+
+go 1.19
+
+require (
+	github.com/foo/tpl/sub v0.0.0 // github.com/foo/tpl/sub
+	github.com/foo/tpl2 v1.0.0
+)
+
+require github.com/foo/tpl/pkg v1.0.0
+
+replace github.com/foo/tpl/sub => ../sub
+
+replace (
+	"github.com/foo/tpl/pkg" v1.0.0 => github.com/foo/tpl/pkg v1.1.0
+	github.com/foo/tpl2 => ../tpl2
+)
+
+exclude github.com/foo/tpl/pkg v0.9.0
+`
+	wantGoMod := `module "example.com/svc"
+
+go 1.19
+
+require (
+	example.com/svc/sub v0.0.0 // github.com/foo/tpl/sub
+	github.com/foo/tpl2 v1.0.0
+)
+
+require example.com/svc/pkg v1.0.0
+
+replace example.com/svc/sub => ../sub
+
+replace (
+	"example.com/svc/pkg" v1.0.0 => example.com/svc/pkg v1.1.0
+	github.com/foo/tpl2 => ../tpl2
+)
+
+exclude example.com/svc/pkg v0.9.0
+`
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":          goMod,
+		"sub/go.mod":      "module `github.com/foo/tpl/sub` // sub module\n",
+		"main.go":         "package main\n\nimport (\n\tv1 \"github.com/foo/tpl/api/v1\"\n\t\"github.com/foo/tpl2/x\"\n)\n\n// github.com/foo/tpl\nvar _, _ = v1.A, x.B\n",
+		"api/v1/v1.proto": "syntax = \"proto3\";\noption go_package = \"github.com/foo/tpl/api/v1;v1\";\n",
+	})
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetModulePath("github.com/foo/tpl", "example.com/svc")
+	r.SetInPlace(false)
+	if err = r.SaveFiles(); err != nil {
+		t.Fatal(err)
+	}
+	checkTestFiles(t, dir, map[string]string{
+		"go.mod":          wantGoMod,
+		"sub/go.mod":      "module `example.com/svc/sub` // sub module\n",
+		"main.go":         "package main\n\nimport (\n\tv1 \"example.com/svc/api/v1\"\n\t\"github.com/foo/tpl2/x\"\n)\n\n// github.com/foo/tpl\nvar _, _ = v1.A, x.B\n",
+		"api/v1/v1.proto": "syntax = \"proto3\";\noption go_package = \"example.com/svc/api/v1;v1\";\n",
+	})
+
+	// nothing is saved if there are invalid go files
+	dir = t.TempDir()
+	files := map[string]string{
+		"go.mod": "module github.com/foo/tpl\n",
+		"bad.go": "package main\nfunc {",
+	}
+	writeTestFiles(t, dir, files)
+	r, err = New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetModulePath("github.com/foo/tpl", "example.com/svc")
+	r.SetInPlace(false)
+	if err = r.SaveFiles(); err == nil {
+		t.Fatal("expect error")
+	}
+	checkTestFiles(t, dir, files)
+}
+
+func TestIgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		".gotoolignore":         "# generated files\n**/*.pb.go\ndocs/**\n!docs/keep.md\nbuild/\n",
+		"user.go":               "user",
+		"api/user.pb.go":        "user",
+		"docs/user.md":          "user",
+		"docs/keep.md":          "user",
+		"build/user.txt":        "user",
+		"vendor/user.go":        "user",
+		"myvendorlib/user.go":   "user",
+		"internal/model/a.txt":  "user",
+		"internal/modelx/a.txt": "user",
+		"go.sum":                "user",
+	})
+	r, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.LoadIgnoreFiles(); err != nil {
+		t.Fatal(err)
+	}
+	r.SetIgnoreFiles("go.sum")
+	r.SetIgnoreSubDirs("vendor", "internal/model")
+	r.SetReplacementFields([]Field{{Old: "user", New: "order", IsCaseSensitive: true}})
+	changes, err := r.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.OldPath)
+	}
+	want := []string{"docs/keep.md", "internal/modelx/a.txt", "myvendorlib/user.go", "user.go"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got changed files %v, want %v", got, want)
+	}
+}
+
+func TestNewIgnoreRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.pb.go", "api/v1/user.pb.go", false, true},
+		{"**/*.pb.go", "user.pb.go", false, true},
+		{"api/*.go", "api/v1/user.go", false, false},
+		{"/build", "build", true, true},
+		{"/build", "cmd/build", true, false},
+		{"build/", "build", false, false},
+		{"docs/**", "docs/a/b.md", false, true},
+		{"docs/**", "docs", true, false},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**/b", "a/b", false, true},
+		{"file?.[ch]", "file1.c", false, true},
+		{"file[!0-9].c", "file1.c", false, false},
+		{"vendor", "myvendorlib", true, false},
+	}
+	for _, tt := range tests {
+		rule, ok := newIgnoreRule(tt.pattern)
+		if !ok {
+			t.Fatalf("invalid pattern %s", tt.pattern)
+		}
+		if _, got := matchRules([]ignoreRule{rule}, tt.path, tt.isDir); got != tt.want {
+			t.Errorf("pattern %s match %s = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryAndSymlink(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00user"
+	for _, inPlace := range []bool{false, true} {
+		src := t.TempDir()
+		writeTestFiles(t, src, map[string]string{
+			"user.txt":      "user",
+			"bin/user.sh":   "echo user",
+			"bin/logo.png":  png,
+			"bin/data.bin":  "user\x00",
+			"docs/note.txt": "user",
+		})
+		if err := os.Chmod(filepath.Join(src, "bin/user.sh"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filepath.Join(src, "bin"), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("user.txt", filepath.Join(src, "link.txt")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(src, "docs"), filepath.Join(src, "docs-link")); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := New(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetReplacementFields([]Field{{Old: "user", New: "order", IsCaseSensitive: true}})
+		out := filepath.Join(t.TempDir(), "out")
+		if inPlace {
+			r.SetInPlace(false)
+			out = src
+		} else if err = r.SetOutDir(out); err != nil {
+			t.Fatal(err)
+		}
+		if err = r.SaveFiles(); err != nil {
+			t.Fatal(err)
+		}
+
+		checkTestFiles(t, out, map[string]string{
+			"order.txt":     "order",
+			"bin/order.sh":  "echo order",
+			"bin/logo.png":  png,
+			"bin/data.bin":  "user\x00",
+			"docs/note.txt": "order",
+		})
+		if info, err := os.Stat(filepath.Join(out, "bin/order.sh")); err != nil || info.Mode().Perm() != 0755 {
+			t.Errorf("inPlace=%v, got file mode %v, %v", inPlace, info.Mode(), err)
+		}
+		if info, err := os.Stat(filepath.Join(out, "bin")); err != nil || info.Mode().Perm() != 0750 {
+			t.Errorf("inPlace=%v, got dir mode %v, %v", inPlace, info.Mode(), err)
+		}
+		if target, err := os.Readlink(filepath.Join(out, "link.txt")); err != nil || target != "order.txt" {
+			t.Errorf("inPlace=%v, got link target %q, %v", inPlace, target, err)
+		}
+		if target, err := os.Readlink(filepath.Join(out, "docs-link")); err != nil || target != filepath.Join(src, "docs") {
+			t.Errorf("inPlace=%v, got link target %q, %v", inPlace, target, err)
+		}
+	}
+}
+
+func TestSaveFilesParallel(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{}
+	want := map[string]string{}
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("dir%d/user%02d.txt", i%5, i)
+		files[name] = "user"
+		want[strings.Replace(name, "user", "order", 1)] = "order"
+	}
+	writeTestFiles(t, src, files)
+
+	// 输出目录中已存在同名目录，这两个文件保存失败，其他文件正常保存
+	out := filepath.Join(t.TempDir(), "out")
+	for _, name := range []string{"dir3/order08.txt", "dir1/order01.txt"} {
+		if err := os.MkdirAll(filepath.Join(out, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		delete(want, name)
+	}
+
+	r, err := New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetReplacementFields([]Field{{Old: "user", New: "order", IsCaseSensitive: true}})
+	r.SetWorkers(4)
+	var calls, total int
+	r.SetProgress(func(done int, n int) {
+		calls++
+		if done != calls {
+			t.Errorf("got done %d, want %d", done, calls)
+		}
+		total = n
+	})
+	if err = r.SetOutDir(out); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.SaveFiles()
+	fileErrs, ok := err.(FileErrors)
+	if !ok || len(fileErrs) != 2 {
+		t.Fatalf("got error %v, want 2 file errors", err)
+	}
+	if fileErrs[0].File != "dir1/user01.txt" || fileErrs[1].File != "dir3/user08.txt" {
+		t.Errorf("got error files %s, %s", fileErrs[0].File, fileErrs[1].File)
+	}
+	if calls != 50 || total != 50 {
+		t.Errorf("got progress calls %d, total %d", calls, total)
+	}
+	checkTestFiles(t, out, want)
+}
+
+func TestReport(t *testing.T) {
+	src := t.TempDir()
+	writeTestFiles(t, src, map[string]string{
+		".gotoolignore":     "*.log\n",
+		"user.txt":          "user user",
+		"a.txt":             "nothing",
+		"logo.png":          "\x89PNG\r\n\x1a\n\x00\x00user",
+		"debug.log":         "user",
+		"go.sum":            "user",
+		"vendor/a/user.go":  "user",
+		"vendor/b/order.go": "user",
+	})
+	r, err := New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.LoadIgnoreFiles(); err != nil {
+		t.Fatal(err)
+	}
+	r.SetIgnoreFiles("go.sum")
+	r.SetIgnoreSubDirs("vendor")
+	r.SetReplacementFields([]Field{
+		{Old: "user", New: "order", IsCaseSensitive: true},
+		{Old: "none", New: "some", IsCaseSensitive: true},
+	})
+	if _, err = r.DryRun(); err != nil {
+		t.Fatal(err)
+	}
+
+	rp := r.Report()
+	if rp.Processed != 4 || rp.Changed != 1 || rp.Renamed != 1 || rp.Skipped != 4 || rp.Failed != 0 || rp.Replacements != 2 {
+		t.Errorf("got report %+v", rp)
+	}
+	// 首字母大小写分别作为替换字段
+	wantFields := []Replacement{{"User", "Order", 0}, {"user", "order", 2}, {"None", "Some", 0}, {"none", "some", 0}}
+	if fmt.Sprint(rp.Fields) != fmt.Sprint(wantFields) {
+		t.Errorf("got fields %+v", rp.Fields)
+	}
+
+	wantFiles := map[string]string{
+		".gotoolignore": "",
+		"a.txt":         "",
+		"debug.log":     SkipIgnoreRule,
+		"go.sum":        SkipIgnoredFile,
+		"logo.png":      SkipBinary,
+		"user.txt":      "",
+		"vendor/":       SkipIgnoredDir,
+	}
+	if len(rp.Files) != len(wantFiles) {
+		t.Errorf("got files %+v", rp.Files)
+	}
+	for _, file := range rp.Files {
+		if skipped, ok := wantFiles[file.Path]; !ok || skipped != file.Skipped {
+			t.Errorf("got file %+v", file)
+		}
+		if file.Path == "user.txt" && (file.NewPath != "order.txt" || len(file.Replacements) != 1) {
+			t.Errorf("got file %+v", file)
+		}
+	}
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	if err = rp.WriteJSON(reportFile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"newPath": "order.txt"`) || !strings.Contains(string(data), `"skipped": "ignored dir"`) {
+		t.Errorf("got report json %s", data)
+	}
+}
@@ -0,0 +1,107 @@
+package replacer
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// 跳过的原因
+const (
+	SkipIgnoredDir  = "ignored dir"  // 所在目录被SetIgnoreSubDirs忽略
+	SkipIgnoredFile = "ignored file" // 文件被SetIgnoreFiles忽略
+	SkipIgnoreRule  = "ignore rule"  // 文件或所在目录被.gitignore等文件的规则忽略
+	SkipBinary      = "binary"       // 二进制文件原样复制，不替换内容
+	SkipTooLarge    = "too large"    // 超过64MB的文件原样复制，不替换内容
+)
+
+// Report 替换报告，包括每个文件的处理结果和每个替换字段的替换次数
+type Report struct {
+	Processed    int           `json:"processed"`    // 处理的文件数，不包括忽略的文件
+	Changed      int           `json:"changed"`      // 内容或路径有变化的文件数
+	Renamed      int           `json:"renamed"`      // 文件名或文件夹名被替换的文件数
+	Skipped      int           `json:"skipped"`      // 忽略的文件和目录数，以及原样复制的二进制文件和过大的文件数
+	Failed       int           `json:"failed"`       // 失败的文件数
+	Replacements int           `json:"replacements"` // 文件内容的总替换次数
+	Fields       []Replacement `json:"fields"`       // 模块路径和每个替换字段在所有文件中的替换次数
+	Files        []FileReport  `json:"files"`        // 按文件顺序排列
+}
+
+// FileReport 单个文件的处理结果，忽略的目录只记录一次
+type FileReport struct {
+	Path         string        `json:"path"`                   // 原文件相对路径，忽略的目录以/结尾
+	NewPath      string        `json:"newPath,omitempty"`      // 替换文件名或文件夹名后的相对路径，没有变化时为空
+	Skipped      string        `json:"skipped,omitempty"`      // 跳过的原因，例如ignored dir、ignored file、binary、too large
+	Replacements []Replacement `json:"replacements,omitempty"` // 文件内容中替换次数不为0的模块路径和替换字段
+	Error        string        `json:"error,omitempty"`        // 处理失败的原因
+}
+
+// Report 获取最近一次SaveFiles或DryRun的替换报告，没有执行时返回nil
+func (r *replacerInfo) Report() *Report {
+	return r.report
+}
+
+// WriteJSON 把报告以JSON格式保存到文件
+func (rp *Report) WriteJSON(file string) error {
+	data, err := json.MarshalIndent(rp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// 文件内容中替换次数不为0的模块路径和替换字段
+func (r *replacerInfo) fileReplacements(f *replacedFile) []Replacement {
+	var reps []Replacement
+	if f.modules > 0 {
+		reps = append(reps, Replacement{Old: r.oldModulePath, New: r.newModulePath, Count: f.modules})
+	}
+	for i, field := range r.replacementFields {
+		if f.counts[i] > 0 {
+			reps = append(reps, Replacement{Old: field.Old, New: field.New, Count: f.counts[i]})
+		}
+	}
+	return reps
+}
+
+// 汇总每个文件的结果，files按文件顺序排列，没有记录的位置为nil，modules和counts是模块路径和每个替换字段的总替换次数
+func (r *replacerInfo) newReport(files []*FileReport, modules int, counts []int) *Report {
+	rp := &Report{Fields: []Replacement{}, Files: []FileReport{}}
+	if r.oldModulePath != "" {
+		rp.Fields = append(rp.Fields, Replacement{Old: r.oldModulePath, New: r.newModulePath, Count: modules})
+	}
+	for i, field := range r.replacementFields {
+		rp.Fields = append(rp.Fields, Replacement{Old: field.Old, New: field.New, Count: counts[i]})
+	}
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		rp.Files = append(rp.Files, *file)
+
+		switch {
+		case file.Error != "":
+			rp.Failed++
+			continue
+		case file.Skipped == SkipBinary || file.Skipped == SkipTooLarge:
+			rp.Processed++
+			rp.Skipped++
+		case file.Skipped != "":
+			rp.Skipped++
+			continue
+		default:
+			rp.Processed++
+		}
+
+		if file.NewPath != "" {
+			rp.Renamed++
+		}
+		if file.NewPath != "" || len(file.Replacements) > 0 {
+			rp.Changed++
+		}
+		for _, rep := range file.Replacements {
+			rp.Replacements += rep.Count
+		}
+	}
+	return rp
+}
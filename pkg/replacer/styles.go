@@ -0,0 +1,67 @@
+package replacer
+
+import (
+	"strings"
+
+	"github.com/huandu/xstrings"
+	"github.com/jinzhu/inflection"
+)
+
+// 命名风格，参数是小写的单词
+var namingStyles = []func(words []string) string{
+	func(words []string) string { return strings.Join(words, "_") },                           // user_order
+	func(words []string) string { return strings.ToUpper(strings.Join(words, "_")) },          // USER_ORDER
+	func(words []string) string { return strings.Join(words, "-") },                           // user-order
+	func(words []string) string { return strings.Join(words, " ") },                           // user order
+	func(words []string) string { return words[0] + strings.Join(titleWords(words[1:]), "") }, // userOrder
+	func(words []string) string { return strings.Join(titleWords(words), "") },                // UserOrder
+}
+
+// 把字段扩展为所有命名风格和复数形式的字段，例如user_order-->customer_invoice扩展为
+// userOrder-->customerInvoice、USER_ORDERS-->CUSTOMER_INVOICES等，重复的字段只保留一个
+func expandStyles(field Field) []Field {
+	oldWords, newWords := splitWords(field.Old), splitWords(field.New)
+	if len(oldWords) == 0 || len(newWords) == 0 {
+		return []Field{field}
+	}
+
+	fields := []Field{field} // 保留原字段，例如HTTPServer不是任何命名风格
+	exists := map[string]bool{field.Old: true}
+	for _, words := range [][2][]string{
+		{oldWords, newWords},
+		{pluralWords(oldWords), pluralWords(newWords)},
+	} {
+		for _, style := range namingStyles {
+			f := field
+			f.Old, f.New = style(words[0]), style(words[1])
+			if exists[f.Old] {
+				continue
+			}
+			exists[f.Old] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// 按命名风格拆分为小写的单词，例如UserOrder、user-order、USER_ORDER都拆分为user和order
+func splitWords(s string) []string {
+	return strings.FieldsFunc(xstrings.ToSnakeCase(s), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+}
+
+// 最后一个单词转为复数
+func pluralWords(words []string) []string {
+	plural := append([]string{}, words...)
+	plural[len(plural)-1] = inflection.Plural(plural[len(plural)-1])
+	return plural
+}
+
+func titleWords(words []string) []string {
+	titles := make([]string, 0, len(words))
+	for _, w := range words {
+		titles = append(titles, xstrings.FirstRuneToUpper(w))
+	}
+	return titles
+}
@@ -0,0 +1,378 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/parser"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+// DefaultDialTimeout dial timeout of the db connection when the dsn does not set timeout
+var DefaultDialTimeout = 10 * time.Second
+
+// data type in information_schema to mysql type
+var dataTypes = map[string]byte{
+	"tinyint":    mysql.TypeTiny,
+	"bool":       mysql.TypeTiny,
+	"boolean":    mysql.TypeTiny,
+	"smallint":   mysql.TypeShort,
+	"mediumint":  mysql.TypeInt24,
+	"int":        mysql.TypeLong,
+	"integer":    mysql.TypeLong,
+	"bigint":     mysql.TypeLonglong,
+	"float":      mysql.TypeFloat,
+	"double":     mysql.TypeDouble,
+	"real":       mysql.TypeDouble,
+	"decimal":    mysql.TypeNewDecimal,
+	"numeric":    mysql.TypeNewDecimal,
+	"char":       mysql.TypeString,
+	"binary":     mysql.TypeString,
+	"varchar":    mysql.TypeVarchar,
+	"varbinary":  mysql.TypeVarchar,
+	"tinytext":   mysql.TypeTinyBlob,
+	"tinyblob":   mysql.TypeTinyBlob,
+	"text":       mysql.TypeBlob,
+	"blob":       mysql.TypeBlob,
+	"mediumtext": mysql.TypeMediumBlob,
+	"mediumblob": mysql.TypeMediumBlob,
+	"longtext":   mysql.TypeLongBlob,
+	"longblob":   mysql.TypeLongBlob,
+	"date":       mysql.TypeDate,
+	"datetime":   mysql.TypeDatetime,
+	"timestamp":  mysql.TypeTimestamp,
+	"time":       mysql.TypeDuration,
+	"year":       mysql.TypeYear,
+	"json":       mysql.TypeJSON,
+	"enum":       mysql.TypeEnum,
+	"set":        mysql.TypeSet,
+	"bit":        mysql.TypeBit,
+}
+
+// GetSchemaFromInformationSchema get the schema of tables from information_schema of mysql, tidb or mariadb,
+// if tableNames is empty, get all tables of the database in the dsn.
+func GetSchemaFromInformationSchema(ctx context.Context, dsn string, tableNames []string, options ...Option) (*Schema, error) {
+	opt := parseOption(options)
+
+	db, dbName, err := openDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close() //nolint
+	if dbName == "" {
+		return nil, errors.New("miss database name in dsn")
+	}
+
+	tables, err := queryTables(ctx, db, dbName, tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	for _, table := range tables {
+		table.Name = goTableName(table.RawName, opt)
+		if err = queryColumns(ctx, db, dbName, table); err != nil {
+			return nil, err
+		}
+		if err = queryKeys(ctx, db, dbName, table); err != nil {
+			return nil, err
+		}
+		if err = queryChecks(ctx, db, dbName, table); err != nil {
+			return nil, err
+		}
+		for _, column := range table.Columns {
+			setGoField(column, opt)
+		}
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	return schema, nil
+}
+
+// parse dsn and return the database name in it, set dial timeout if it is not set
+func openDB(dsn string) (*sql.DB, string, error) {
+	cfg, err := mysqlDriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "parse dsn error")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultDialTimeout
+	}
+
+	connector, err := mysqlDriver.NewConnector(cfg)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "open db error")
+	}
+	return sql.OpenDB(connector), cfg.DBName, nil
+}
+
+func queryTables(ctx context.Context, db *sql.DB, dbName string, tableNames []string) ([]*Table, error) {
+	query := "SELECT TABLE_NAME, TABLE_COMMENT FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'"
+	args := []interface{}{dbName}
+	if len(tableNames) > 0 {
+		query += " AND TABLE_NAME IN (?" + strings.Repeat(",?", len(tableNames)-1) + ")"
+		for _, name := range tableNames {
+			args = append(args, name)
+		}
+	}
+	query += " ORDER BY TABLE_NAME"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query tables error")
+	}
+	defer rows.Close() //nolint
+
+	found := make(map[string]*Table)
+	var tables []*Table
+	for rows.Next() {
+		table := &Table{}
+		if err = rows.Scan(&table.RawName, &table.Comment); err != nil {
+			return nil, err
+		}
+		found[table.RawName] = table
+		tables = append(tables, table)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(tableNames) == 0 {
+		return tables, nil
+	}
+	// keep the order of the table names
+	tables = tables[:0]
+	for _, name := range tableNames {
+		table, ok := found[name]
+		if !ok {
+			return nil, errors.Errorf("table(%s) not found", name)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func queryColumns(ctx context.Context, db *sql.DB, dbName string, table *Table) error {
+	rows, err := db.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY,
+EXTRA, COLUMN_COMMENT, IFNULL(GENERATION_EXPRESSION, ''), PRIVILEGES
+FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, dbName, table.RawName)
+	if err != nil {
+		return errors.WithMessage(err, "query columns error")
+	}
+	defer rows.Close() //nolint
+
+	for rows.Next() {
+		row := columnRow{}
+		err = rows.Scan(&row.name, &row.dataType, &row.columnType, &row.isNullable, &row.defaultVal, &row.columnKey,
+			&row.extra, &row.comment, &row.generation, &row.privileges)
+		if err != nil {
+			return err
+		}
+		table.Columns = append(table.Columns, newInfoSchemaColumn(row))
+	}
+	return rows.Err()
+}
+
+// a row of information_schema.COLUMNS
+type columnRow struct {
+	name       string
+	dataType   string
+	columnType string
+	isNullable string
+	defaultVal sql.NullString
+	columnKey  string
+	extra      string
+	comment    string
+	generation string
+	privileges string
+}
+
+// convert the row to the column, keep the column the same as parsed from the DDL of SHOW CREATE TABLE
+func newInfoSchemaColumn(row columnRow) *Column {
+	column := &Column{
+		Name:            row.name,
+		DataType:        strings.ToLower(row.dataType),
+		DBType:          row.columnType,
+		Comment:         row.comment,
+		IsNullable:      row.isNullable == "YES",
+		IsPrimaryKey:    row.columnKey == "PRI",
+		IsAutoIncrement: strings.Contains(strings.ToLower(row.extra), "auto_increment"),
+		IsGenerated:     row.generation != "",
+		Generation:      row.generation,
+	}
+	column.IsUnsigned = strings.Contains(strings.ToLower(column.DBType), "unsigned")
+	// SHOW CREATE TABLE writes NULL only for nullable timestamp columns, other nullable columns are written as DEFAULT NULL
+	column.explicitNull = column.IsNullable && column.DataType == "timestamp"
+	column.Default, column.HasDefault = normalizeDefault(row.defaultVal)
+	if row.privileges != "" {
+		column.Privileges = strings.Split(row.privileges, ",")
+	}
+
+	column.tp = newFieldType(column.DataType, column.DBType)
+	if column.tp.Tp != mysql.TypeNull {
+		column.DBType = column.tp.InfoSchemaStr()
+	}
+	return column
+}
+
+func queryKeys(ctx context.Context, db *sql.DB, dbName string, table *Table) error {
+	rows, err := db.QueryContext(ctx, `SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX`, dbName, table.RawName)
+	if err != nil {
+		return errors.WithMessage(err, "query keys error")
+	}
+	defer rows.Close() //nolint
+
+	var indexRows []indexRow
+	for rows.Next() {
+		var row indexRow
+		var columnName sql.NullString // null in functional key parts of mysql 8.0
+		var nonUnique int
+		if err = rows.Scan(&row.indexName, &nonUnique, &columnName); err != nil {
+			return err
+		}
+		if !columnName.Valid {
+			continue
+		}
+		row.nonUnique = nonUnique != 0
+		row.columnName = columnName.String
+		indexRows = append(indexRows, row)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	setTableKeys(table, indexRows)
+	return nil
+}
+
+// a row of information_schema.STATISTICS
+type indexRow struct {
+	indexName  string
+	nonUnique  bool
+	columnName string
+}
+
+// set the keys of the table from the rows ordered by index name and sequence in index
+func setTableKeys(table *Table, rows []indexRow) {
+	var names []string
+	nonUniques := make(map[string]bool)
+	keys := make(map[string][]string)
+	for _, row := range rows {
+		if _, ok := keys[row.indexName]; !ok {
+			names = append(names, row.indexName)
+		}
+		keys[row.indexName] = append(keys[row.indexName], row.columnName)
+		nonUniques[row.indexName] = row.nonUnique
+	}
+
+	for _, name := range names {
+		switch {
+		case name == "PRIMARY":
+			table.PrimaryKeys = keys[name]
+		case !nonUniques[name]:
+			table.UniqueKeys = append(table.UniqueKeys, keys[name])
+		default:
+			table.Indexes = append(table.Indexes, keys[name])
+		}
+	}
+
+	for _, column := range table.Columns {
+		column.IsPrimaryKey = column.IsPrimaryKey || inStrings(table.PrimaryKeys, column.Name)
+	}
+	// only the first column of the primary key is tagged primary_key in the gorm tag, the same as the DDL
+	if len(table.PrimaryKeys) > 0 {
+		if column, ok := table.Column(table.PrimaryKeys[0]); ok {
+			column.isPrimaryKeyTag = true
+		}
+	}
+	for _, keys := range table.UniqueKeys {
+		if len(keys) != 1 {
+			continue
+		}
+		if column, ok := table.Column(keys[0]); ok {
+			column.IsUnique = true
+		}
+	}
+}
+
+// check constraints are supported since mysql 8.0.16 and mariadb 10.2,
+// ignore them if information_schema.CHECK_CONSTRAINTS does not exist in other versions and tidb
+func queryChecks(ctx context.Context, db *sql.DB, dbName string, table *Table) error {
+	rows, err := db.QueryContext(ctx, `SELECT tc.CONSTRAINT_NAME, cc.CHECK_CLAUSE FROM information_schema.TABLE_CONSTRAINTS tc
+JOIN information_schema.CHECK_CONSTRAINTS cc ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK' ORDER BY tc.CONSTRAINT_NAME`, dbName, table.RawName)
+	if err != nil {
+		if isNoSuchTable(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "query check constraints error")
+	}
+	defer rows.Close() //nolint
+
+	for rows.Next() {
+		check := &Check{}
+		if err = rows.Scan(&check.Name, &check.Clause); err != nil {
+			return err
+		}
+		table.Checks = append(table.Checks, check)
+	}
+	return rows.Err()
+}
+
+// error 1146 table doesn't exist, 1109 unknown table in information_schema
+func isNoSuchTable(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1146 || mysqlErr.Number == 1109
+	}
+	return false
+}
+
+// mariadb quotes string default values, shows NULL default as 'NULL' and functions with brackets,
+// e.g. 'abc', NULL, current_timestamp(). an empty default is treated as no default, the same as the DDL
+func normalizeDefault(value sql.NullString) (string, bool) {
+	if !value.Valid || value.String == "NULL" {
+		return "", false
+	}
+
+	v := value.String
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		v = strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+		return v, v != ""
+	}
+	if v == "" {
+		return "", false
+	}
+	if strings.EqualFold(v, "current_timestamp()") {
+		return "CURRENT_TIMESTAMP", true
+	}
+	return v, true
+}
+
+// parse the COLUMN_TYPE in the same way as the DDL to get the length, elements of enum and set and flags,
+// e.g. varchar(64), enum('a','b'), bit(1), bigint unsigned, use the DATA_TYPE if it can not be parsed
+func newFieldType(dataType string, columnType string) *types.FieldType {
+	stmts, err := parser.New().Parse("CREATE TABLE t (c "+columnType+")", "", "")
+	if err == nil && len(stmts) == 1 {
+		if ct, ok := stmts[0].(*ast.CreateTableStmt); ok && len(ct.Cols) == 1 {
+			return ct.Cols[0].Tp
+		}
+	}
+
+	tp, ok := dataTypes[dataType]
+	if !ok {
+		tp = mysql.TypeNull // unsupported type
+	}
+	ft := types.NewFieldType(tp)
+	if strings.Contains(strings.ToLower(columnType), "unsigned") {
+		ft.Flag |= mysql.UnsignedFlag
+	}
+	return ft
+}
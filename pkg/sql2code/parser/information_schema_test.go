@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenDB(t *testing.T) {
+	db, dbName, err := openDB("root:123456@(192.168.3.37:3306)/test")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", dbName)
+	_ = db.Close()
+
+	db, dbName, err = openDB("root:123456@(192.168.3.37:3306)/")
+	assert.NoError(t, err)
+	assert.Equal(t, "", dbName)
+	_ = db.Close()
+
+	_, _, err = openDB("root:123456@192.168.3.37:3306")
+	assert.Error(t, err)
+}
+
+func TestGetSchemaFromInformationSchema(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetSchemaFromInformationSchema(ctx, "root:123456@(127.0.0.1:3306)/test", []string{"user"})
+	assert.Error(t, err)
+
+	_, err = GetSchemaFromInformationSchema(context.Background(), "root:123456@(127.0.0.1:3306)/", []string{"user"})
+	assert.EqualError(t, err, "miss database name in dsn")
+
+	_, err = GetSchemaFromDB(ctx, "root:123456@(127.0.0.1:3306)/test", []string{"user"})
+	assert.Error(t, err)
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	tests := []struct {
+		value   sql.NullString
+		want    string
+		wantHas bool
+	}{
+		{sql.NullString{}, "", false},
+		{sql.NullString{String: "NULL", Valid: true}, "", false},                                // mariadb
+		{sql.NullString{String: "'abc'", Valid: true}, "abc", true},                             // mariadb
+		{sql.NullString{String: "'it''s'", Valid: true}, "it's", true},                          // mariadb
+		{sql.NullString{String: "current_timestamp()", Valid: true}, "CURRENT_TIMESTAMP", true}, // mariadb
+		{sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, "CURRENT_TIMESTAMP", true},
+		{sql.NullString{String: "abc", Valid: true}, "abc", true},
+		{sql.NullString{String: "", Valid: true}, "", false},
+		{sql.NullString{String: "''", Valid: true}, "", false}, // mariadb
+	}
+	for _, tt := range tests {
+		got, has := normalizeDefault(tt.value)
+		assert.Equal(t, tt.want, got, tt.value.String)
+		assert.Equal(t, tt.wantHas, has, tt.value.String)
+	}
+}
+
+func TestIsNoSuchTable(t *testing.T) {
+	assert.True(t, isNoSuchTable(&mysqlDriver.MySQLError{Number: 1146, Message: "Table 'information_schema.CHECK_CONSTRAINTS' doesn't exist"}))
+	assert.True(t, isNoSuchTable(errors.WithMessage(&mysqlDriver.MySQLError{Number: 1109}, "query")))
+	assert.False(t, isNoSuchTable(&mysqlDriver.MySQLError{Number: 1045, Message: "Access denied"}))
+	assert.False(t, isNoSuchTable(context.DeadlineExceeded))
+}
+
+func TestNewFieldType(t *testing.T) {
+	goType, _ := mysqlToGoType(newFieldType("bigint", "bigint unsigned"), NullDisable)
+	assert.Equal(t, "uint64", goType)
+	goType, _ = mysqlToGoType(newFieldType("int", "int(11)"), NullInPointer)
+	assert.Equal(t, "*int", goType)
+	goType, pkg := mysqlToGoType(newFieldType("datetime", "datetime(3)"), NullDisable)
+	assert.Equal(t, "time.Time", goType)
+	assert.Equal(t, "time", pkg)
+	goType, _ = mysqlToGoType(newFieldType("varchar", "varchar(64)"), NullInSql)
+	assert.Equal(t, "sql.NullString", goType)
+	goType, _ = mysqlToGoType(newFieldType("geometry", "geometry"), NullDisable)
+	assert.Equal(t, "UnSupport", goType)
+
+	ft := newFieldType("enum", "enum('a','it''s')")
+	assert.Equal(t, []string{"a", "it's"}, ft.Elems)
+	assert.Equal(t, `"a" | "it's"`, mysqlToTSType(ft))
+	ft = newFieldType("bit", "bit(1)")
+	assert.Equal(t, 1, ft.Flen)
+	assert.Equal(t, "boolean", mysqlToTSType(ft))
+	ft = newFieldType("char", "char(36)")
+	assert.Equal(t, 36, ft.Flen)
+}
+
+// the DDL of SHOW CREATE TABLE and the rows in information_schema of the same tables
+const parityDDL = "CREATE TABLE `user_order` (\n" +
+	"  `order_id` bigint unsigned NOT NULL,\n" +
+	"  `item_id` int NOT NULL,\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'item name',\n" +
+	"  `status` enum('new','paid','it''s') NOT NULL DEFAULT 'new',\n" +
+	"  `is_gift` bit(1) DEFAULT NULL,\n" +
+	"  `remark` varchar(255) DEFAULT NULL,\n" +
+	"  `price` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+	"  `paid_at` timestamp NULL DEFAULT NULL,\n" +
+	"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`order_id`,`item_id`),\n" +
+	"  UNIQUE KEY `uk_name` (`name`),\n" +
+	"  KEY `idx_status` (`status`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='user order';\n" +
+	"CREATE TABLE `user` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `created_at` datetime DEFAULT NULL,\n" +
+	"  `updated_at` datetime DEFAULT NULL,\n" +
+	"  `deleted_at` datetime DEFAULT NULL,\n" +
+	"  `name` char(36) NOT NULL,\n" +
+	"  `age` tinyint unsigned NOT NULL DEFAULT '0',\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+var parityTables = []struct {
+	name    string
+	comment string
+	columns []columnRow
+	indexes []indexRow
+}{
+	{
+		name:    "user_order",
+		comment: "user order",
+		columns: []columnRow{
+			{name: "order_id", dataType: "bigint", columnType: "bigint unsigned", isNullable: "NO", columnKey: "PRI"},
+			{name: "item_id", dataType: "int", columnType: "int", isNullable: "NO", columnKey: "PRI"},
+			{name: "name", dataType: "varchar", columnType: "varchar(64)", isNullable: "NO", columnKey: "UNI",
+				defaultVal: sql.NullString{String: "", Valid: true}, comment: "item name"},
+			{name: "status", dataType: "enum", columnType: "enum('new','paid','it''s')", isNullable: "NO", columnKey: "MUL",
+				defaultVal: sql.NullString{String: "new", Valid: true}},
+			{name: "is_gift", dataType: "bit", columnType: "bit(1)", isNullable: "YES"},
+			{name: "remark", dataType: "varchar", columnType: "varchar(255)", isNullable: "YES"},
+			{name: "price", dataType: "decimal", columnType: "decimal(10,2)", isNullable: "NO",
+				defaultVal: sql.NullString{String: "0.00", Valid: true}},
+			{name: "paid_at", dataType: "timestamp", columnType: "timestamp", isNullable: "YES"},
+			{name: "created_at", dataType: "datetime", columnType: "datetime", isNullable: "NO",
+				defaultVal: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, extra: "DEFAULT_GENERATED"},
+		},
+		indexes: []indexRow{
+			{indexName: "PRIMARY", columnName: "order_id"},
+			{indexName: "PRIMARY", columnName: "item_id"},
+			{indexName: "idx_status", nonUnique: true, columnName: "status"},
+			{indexName: "uk_name", columnName: "name"},
+		},
+	},
+	{
+		name: "user",
+		columns: []columnRow{
+			{name: "id", dataType: "bigint", columnType: "bigint unsigned", isNullable: "NO", columnKey: "PRI", extra: "auto_increment"},
+			{name: "created_at", dataType: "datetime", columnType: "datetime", isNullable: "YES"},
+			{name: "updated_at", dataType: "datetime", columnType: "datetime", isNullable: "YES"},
+			{name: "deleted_at", dataType: "datetime", columnType: "datetime", isNullable: "YES"},
+			{name: "name", dataType: "char", columnType: "char(36)", isNullable: "NO"},
+			{name: "age", dataType: "tinyint", columnType: "tinyint unsigned", isNullable: "NO",
+				defaultVal: sql.NullString{String: "0", Valid: true}},
+		},
+		indexes: []indexRow{
+			{indexName: "PRIMARY", columnName: "id"},
+		},
+	},
+}
+
+var parityOptions = [][]Option{
+	nil,
+	{WithNullStyle(NullInPointer), WithGormType()},
+	{WithNullStyle(NullInSql), WithJSONTag(1), WithEmbed()},
+	{WithNullStyle(NullDisable), WithJSONTag(0), WithPackage("user"), WithForceTableName()},
+}
+
+func TestInformationSchemaParity(t *testing.T) {
+	for i, options := range parityOptions {
+		opt := parseOption(options)
+		schema := &Schema{}
+		for _, pt := range parityTables {
+			table := &Table{Name: goTableName(pt.name, opt), RawName: pt.name, Comment: pt.comment}
+			for _, row := range pt.columns {
+				table.Columns = append(table.Columns, newInfoSchemaColumn(row))
+			}
+			setTableKeys(table, pt.indexes)
+			for _, column := range table.Columns {
+				setGoField(column, opt)
+			}
+			schema.Tables = append(schema.Tables, table)
+		}
+
+		ddlSchema, err := ParseSchema(parityDDL, options...)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertSameSchema(t, ddlSchema, schema, options, i)
+	}
+}
+
+// load the same tables by SHOW CREATE TABLE and information_schema, e.g.
+// SQL2CODE_TEST_DSN="root:123456@(127.0.0.1:3306)/test" go test -run TestInformationSchemaParityDB
+func TestInformationSchemaParityDB(t *testing.T) {
+	dsn := os.Getenv("SQL2CODE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("SQL2CODE_TEST_DSN is not set")
+	}
+
+	db, _, err := openDB(dsn)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close() //nolint
+	ctx := context.Background()
+	tableNames := []string{"user_order", "user"}
+	for _, createSQL := range strings.Split(parityDDL, ";\n") {
+		if _, err = db.ExecContext(ctx, createSQL); !assert.NoError(t, err) {
+			return
+		}
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE "+strings.Join(tableNames, ", "))
+	}()
+
+	for i, options := range parityOptions {
+		ddlSchema, err := GetSchemaFromDB(ctx, dsn, tableNames, options...)
+		if !assert.NoError(t, err) {
+			return
+		}
+		schema, err := GetSchemaFromInformationSchema(ctx, dsn, tableNames, options...)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertSameSchema(t, ddlSchema, schema, options, i)
+	}
+}
+
+func assertSameSchema(t *testing.T, ddlSchema *Schema, schema *Schema, options []Option, i int) {
+	for _, table := range ddlSchema.Tables {
+		got, ok := schema.Table(table.RawName)
+		if !assert.True(t, ok, table.RawName) {
+			continue
+		}
+		assert.Equal(t, table.PrimaryKeys, got.PrimaryKeys, table.RawName)
+		assert.Equal(t, table.UniqueKeys, got.UniqueKeys, table.RawName)
+		assert.Equal(t, table.Indexes, got.Indexes, table.RawName)
+	}
+
+	want, err := GenerateFromSchema(ddlSchema, options...)
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := GenerateFromSchema(schema, options...)
+	if !assert.NoError(t, err) {
+		return
+	}
+	wantCodes, gotCodes := want.Codes(), got.Codes()
+	for codeType, code := range wantCodes {
+		assert.Equal(t, code, gotCodes[codeType], "options %d, code type %s", i, codeType)
+	}
+}
@@ -1,21 +1,51 @@
 package parser
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 
-	_ "github.com/go-sql-driver/mysql" //nolint
 	"github.com/pkg/errors"
 )
 
 // GetCreateTableFromDB get create table info from mysql
 func GetCreateTableFromDB(dsn, tableName string) (string, error) {
-	db, err := sql.Open("mysql", dsn)
+	return GetCreateTableFromDBContext(context.Background(), dsn, tableName)
+}
+
+// GetCreateTableFromDBContext get create table info from mysql, the query is canceled when ctx is done
+func GetCreateTableFromDBContext(ctx context.Context, dsn, tableName string) (string, error) {
+	db, _, err := openDB(dsn)
 	if err != nil {
-		return "", errors.WithMessage(err, "open db error")
+		return "", err
 	}
 	defer db.Close() //nolint
 
-	rows, err := db.Query("SHOW CREATE TABLE " + tableName)
+	return showCreateTable(ctx, db, tableName)
+}
+
+// GetSchemaFromDB get the schema of tables from mysql by SHOW CREATE TABLE
+func GetSchemaFromDB(ctx context.Context, dsn string, tableNames []string, options ...Option) (*Schema, error) {
+	db, _, err := openDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close() //nolint
+
+	createSQLs := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		createSQL, err := showCreateTable(ctx, db, tableName)
+		if err != nil {
+			return nil, err
+		}
+		createSQLs = append(createSQLs, createSQL+";")
+	}
+
+	return ParseSchema(strings.Join(createSQLs, "\n"), options...)
+}
+
+func showCreateTable(ctx context.Context, db *sql.DB, tableName string) (string, error) {
+	rows, err := db.QueryContext(ctx, "SHOW CREATE TABLE "+tableName)
 	if err != nil {
 		return "", errors.WithMessage(err, "query show create table error")
 	}
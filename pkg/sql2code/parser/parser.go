@@ -7,11 +7,11 @@ import (
 	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
 	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
 	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
-	"github.com/blastrain/vitess-sqlparser/tidbparser/parser"
 	"github.com/huandu/xstrings"
 	"github.com/jinzhu/inflection"
 	"go/format"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -31,6 +31,8 @@ const (
 	CodeTypeProto = "proto"
 	// CodeTypeService grpc service code
 	CodeTypeService = "service"
+	// CodeTypeTypeScript typescript interface code
+	CodeTypeTypeScript = "typescript"
 )
 
 // Codes 生成的代码
@@ -50,37 +52,49 @@ type modelCodes struct {
 
 // ParseSQL 根据sql生成不同用途代码
 func ParseSQL(sql string, options ...Option) (map[string]string, error) {
-	initTemplate()
-	opt := parseOption(options)
+	result, err := Parse(sql, options...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Codes(), nil
+}
 
-	stmts, err := parser.New().Parse(sql, opt.Charset, opt.Collation)
+// Parse 根据sql生成不同用途代码，按表区分代码和元数据
+func Parse(sql string, options ...Option) (*Result, error) {
+	schema, err := ParseSchema(sql, options...)
 	if err != nil {
 		return nil, err
 	}
-	modelStructCodes := make([]string, 0, len(stmts))
-	updateFieldsCodes := make([]string, 0, len(stmts))
-	handlerStructCodes := make([]string, 0, len(stmts))
-	protoFileCodes := make([]string, 0, len(stmts))
-	serviceStructCodes := make([]string, 0, len(stmts))
-	modelJSONCodes := make([]string, 0, len(stmts))
+	return GenerateFromSchema(schema, options...)
+}
+
+// GenerateFromSchema 根据表结构生成不同用途代码，options应该和获取schema时的options保持一致
+func GenerateFromSchema(schema *Schema, options ...Option) (*Result, error) {
+	initTemplate()
+	opt := parseOption(options)
+
+	modelStructCodes := make([]string, 0, len(schema.Tables))
+	tableCodes := make([]*TableCode, 0, len(schema.Tables))
 	importPath := make(map[string]struct{})
-	tableNames := make([]string, 0, len(stmts))
-	for _, stmt := range stmts {
-		if ct, ok := stmt.(*ast.CreateTableStmt); ok {
-			code, err := makeCode(ct, opt) //nolint
-			if err != nil {
-				return nil, err
-			}
-			modelStructCodes = append(modelStructCodes, code.modelStruct)
-			updateFieldsCodes = append(updateFieldsCodes, code.updateFields)
-			handlerStructCodes = append(handlerStructCodes, code.handlerStruct)
-			protoFileCodes = append(protoFileCodes, code.protoFile)
-			serviceStructCodes = append(serviceStructCodes, code.serviceStruct)
-			modelJSONCodes = append(modelJSONCodes, code.modelJSON)
-			tableNames = append(tableNames, toCamel(ct.Table.Name.String()))
-			for _, s := range code.importPaths {
-				importPath[s] = struct{}{}
-			}
+	for _, table := range schema.Tables {
+		code, err := makeCode(table, opt)
+		if err != nil {
+			return nil, err
+		}
+		modelStructCodes = append(modelStructCodes, code.modelStruct)
+		tableCodes = append(tableCodes, &TableCode{
+			Table:       table,
+			ImportPaths: code.importPaths,
+			Model:       code.modelStruct,
+			JSON:        code.modelJSON,
+			DAO:         code.updateFields,
+			Handler:     code.handlerStruct,
+			Proto:       code.protoFile,
+			Service:     code.serviceStruct,
+			TypeScript:  code.tsInterface,
+		})
+		for _, s := range code.importPaths {
+			importPath[s] = struct{}{}
 		}
 	}
 
@@ -100,17 +114,12 @@ func ParseSQL(sql string, options ...Option) (map[string]string, error) {
 		return nil, err
 	}
 
-	var codesMap = map[string]string{
-		CodeTypeModel:   modelCode,
-		CodeTypeJSON:    strings.Join(modelJSONCodes, "\n\n"),
-		CodeTypeDAO:     strings.Join(updateFieldsCodes, "\n\n"),
-		CodeTypeHandler: strings.Join(handlerStructCodes, "\n\n"),
-		CodeTypeProto:   strings.Join(protoFileCodes, "\n\n"),
-		CodeTypeService: strings.Join(serviceStructCodes, "\n\n"),
-		TableName:       strings.Join(tableNames, ", "),
-	}
-
-	return codesMap, nil
+	return &Result{
+		Package:     opt.Package,
+		ImportPaths: importPathArr,
+		Model:       modelCode,
+		Tables:      tableCodes,
+	}, nil
 }
 
 // ConfigureAcronym config acronym
@@ -131,11 +140,54 @@ type tmplData struct {
 }
 
 type tmplField struct {
-	Name    string
-	ColName string
-	GoType  string
-	Tag     string
-	Comment string
+	Name     string
+	ColName  string
+	JSONName string
+	GoType   string
+	TSType   string
+	Nullable bool
+	Tag      string
+	Comment  string
+	// BlankLine followed by an empty line, separates the embedded model from the columns
+	BlankLine bool
+}
+
+// CommentLines table comment split into lines
+func (t tmplData) CommentLines() []string {
+	return commentLines(t.Comment)
+}
+
+// JSDocLines table comment lines that are safe to put in a JSDoc block
+func (t tmplData) JSDocLines() []string {
+	return jsDocLines(t.Comment)
+}
+
+// CommentLines column comment split into lines
+func (t tmplField) CommentLines() []string {
+	return commentLines(t.Comment)
+}
+
+// LeadingComment multi-line column comment, placed above the field as a doc comment
+func (t tmplField) LeadingComment() []string {
+	lines := commentLines(t.Comment)
+	if len(lines) > 1 {
+		return lines
+	}
+	return nil
+}
+
+// TrailingComment single-line column comment, placed at the end of the field
+func (t tmplField) TrailingComment() string {
+	lines := commentLines(t.Comment)
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return ""
+}
+
+// JSDocLines column comment lines that are safe to put in a JSDoc block
+func (t tmplField) JSDocLines() []string {
+	return jsDocLines(t.Comment)
 }
 
 // ConditionZero type of condition 0
@@ -231,54 +283,35 @@ type codeText struct {
 	handlerStruct string
 	protoFile     string
 	serviceStruct string
+	tsInterface   string
 }
 
 // nolint
-func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
+func makeCode(table *Table, opt options) (*codeText, error) {
 	importPath := make([]string, 0, 1)
 	data := tmplData{
-		TableName:    stmt.Table.Name.String(),
-		RawTableName: stmt.Table.Name.String(),
-		Fields:       make([]tmplField, 0, 1),
+		TableName:    table.Name,
+		TName:        firstLetterToLow(table.Name),
+		RawTableName: table.RawName,
+		Comment:      table.Comment,
+		Fields:       make([]tmplField, 0, len(table.Columns)),
 	}
-	tablePrefix := opt.TablePrefix
-	if tablePrefix != "" && strings.HasPrefix(data.TableName, tablePrefix) {
+	if opt.TablePrefix != "" && strings.HasPrefix(data.RawTableName, opt.TablePrefix) {
 		data.NameFunc = true
-		data.TableName = data.TableName[len(tablePrefix):]
 	}
 	if opt.ForceTableName || data.RawTableName != inflection.Plural(data.RawTableName) {
 		data.NameFunc = true
 	}
 
-	data.TableName = toCamel(data.TableName)
-	data.TName = firstLetterToLow(data.TableName)
-
-	// find table comment
-	for _, o := range stmt.Options {
-		if o.Tp == ast.TableOptionComment {
-			data.Comment = o.StrValue
-			break
-		}
-	}
-
-	isPrimaryKey := make(map[string]bool)
-	for _, con := range stmt.Constraints {
-		if con.Tp == ast.ConstraintPrimaryKey {
-			isPrimaryKey[con.Keys[0].Column.String()] = true
-		}
-	}
-
-	columnPrefix := opt.ColumnPrefix
-	for _, col := range stmt.Cols {
-		colName := col.Name.Name.String()
-		goFieldName := colName
-		if columnPrefix != "" && strings.HasPrefix(goFieldName, columnPrefix) {
-			goFieldName = goFieldName[len(columnPrefix):]
-		}
-
+	for _, col := range table.Columns {
+		colName := col.Name
 		field := tmplField{
-			Name:    toCamel(goFieldName),
-			ColName: colName,
+			Name:     col.GoName,
+			ColName:  colName,
+			JSONName: colName,
+			GoType:   col.GoType,
+			Nullable: col.IsNullable,
+			Comment:  col.Comment,
 		}
 
 		tags := make([]string, 0, 4)
@@ -288,66 +321,30 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		gormTag.WriteString(colName)
 		if opt.GormType {
 			gormTag.WriteString(";type:")
-			gormTag.WriteString(col.Tp.InfoSchemaStr())
-		}
-		if isPrimaryKey[colName] {
-			gormTag.WriteString(";primary_key")
-		}
-		isNotNull := false
-		canNull := false
-		for _, o := range col.Options {
-			switch o.Tp {
-			case ast.ColumnOptionPrimaryKey:
-				if !isPrimaryKey[colName] {
-					gormTag.WriteString(";primary_key")
-					isPrimaryKey[colName] = true
-				}
-			case ast.ColumnOptionNotNull:
-				isNotNull = true
-			case ast.ColumnOptionAutoIncrement:
-				gormTag.WriteString(";AUTO_INCREMENT")
-			case ast.ColumnOptionDefaultValue:
-				if value := getDefaultValue(o.Expr); value != "" {
-					gormTag.WriteString(";default:")
-					gormTag.WriteString(value)
-				}
-			case ast.ColumnOptionUniqKey:
-				gormTag.WriteString(";unique")
-			case ast.ColumnOptionNull:
-				//gormTag.WriteString(";NULL")
-				canNull = true
-			case ast.ColumnOptionOnUpdate: // For Timestamp and Datetime only.
-			case ast.ColumnOptionFulltext:
-			case ast.ColumnOptionComment:
-				field.Comment = o.Expr.GetDatum().GetString()
-			default:
-				//return "", nil, errors.Errorf(" unsupport option %d\n", o.Tp)
-			}
+			gormTag.WriteString(col.DBType)
 		}
-		if !isPrimaryKey[colName] && isNotNull {
-			gormTag.WriteString(";NOT NULL")
+		for _, o := range col.gormTagOptions() {
+			gormTag.WriteString(";")
+			gormTag.WriteString(o)
 		}
 		tags = append(tags, "gorm", gormTag.String())
 
+		if opt.JSONNamedType != 0 {
+			field.JSONName = xstrings.FirstRuneToLower(xstrings.ToCamelCase(colName)) // 使用驼峰类型json名称
+		}
 		if opt.JSONTag {
-			if opt.JSONNamedType != 0 {
-				colName = xstrings.FirstRuneToLower(xstrings.ToCamelCase(colName)) // 使用驼峰类型json名称
-			}
-			tags = append(tags, "json", colName)
+			tags = append(tags, "json", field.JSONName)
 		}
 
 		field.Tag = makeTagStr(tags)
 
-		// get type in golang
-		nullStyle := opt.NullStyle
-		if !canNull {
-			nullStyle = NullDisable
-		}
-		goType, pkg := mysqlToGoType(col.Tp, nullStyle)
-		if pkg != "" {
+		if pkg := goTypeImportPath(col.GoType); pkg != "" {
 			importPath = append(importPath, pkg)
 		}
-		field.GoType = goType
+		field.TSType = "unknown"
+		if col.tp != nil {
+			field.TSType = mysqlToTSType(col.tp)
+		}
 
 		data.Fields = append(data.Fields, field)
 	}
@@ -382,6 +379,11 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		return nil, err
 	}
 
+	tsInterfaceCode, err := getTSInterfaceCode(data)
+	if err != nil {
+		return nil, err
+	}
+
 	return &codeText{
 		importPaths:   importPaths,
 		modelStruct:   modelStructCode,
@@ -390,6 +392,7 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		handlerStruct: handlerStructCode,
 		protoFile:     protoFileCode,
 		serviceStruct: serviceStructCode,
+		tsInterface:   tsInterfaceCode,
 	}, nil
 }
 
@@ -400,11 +403,12 @@ func getModelStructCode(data tmplData, importPaths []string, isEmbed bool) (stri
 	if isEmbed {
 		// 嵌入字段
 		newFields = append(newFields, tmplField{
-			Name:    __mysqlModel__,
-			ColName: __mysqlModel__,
-			GoType:  __type__,
-			Tag:     `gorm:"embedded"`,
-			Comment: "embed id and time\n",
+			Name:      __mysqlModel__,
+			ColName:   __mysqlModel__,
+			GoType:    __type__,
+			Tag:       `gorm:"embedded"`,
+			Comment:   "embed id and time",
+			BlankLine: true,
 		})
 
 		isHaveTimeType := false
@@ -619,6 +623,15 @@ func getServiceStructCode(data tmplData) (string, error) {
 	return code, nil
 }
 
+func getTSInterfaceCode(data tmplData) (string, error) {
+	builder := strings.Builder{}
+	err := tsInterfaceTmpl.Execute(&builder, data)
+	if err != nil {
+		return "", fmt.Errorf("tsInterfaceTmpl.Execute error: %v", err)
+	}
+	return builder.String(), nil
+}
+
 func addCommaToJSON(modelJSONCode string) string {
 	r := strings.NewReader(modelJSONCode)
 	buf := bufio.NewReader(r)
@@ -711,6 +724,45 @@ func mysqlToGoType(colTp *types.FieldType, style NullStyle) (name string, path s
 	return
 }
 
+// bigint is mapped to string, number in javascript loses precision above 2^53
+func mysqlToTSType(colTp *types.FieldType) string {
+	switch colTp.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong,
+		mysql.TypeFloat, mysql.TypeDouble:
+		return "number"
+	case mysql.TypeLonglong:
+		return "string"
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString,
+		mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return "string"
+	case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDate:
+		return "string" // time is serialized as string in json
+	case mysql.TypeDecimal, mysql.TypeNewDecimal:
+		return "string"
+	case mysql.TypeJSON:
+		return "string"
+	case mysql.TypeEnum: // union of the enum values
+		if len(colTp.Elems) == 0 {
+			return "string"
+		}
+		values := make([]string, 0, len(colTp.Elems))
+		for _, elem := range colTp.Elems {
+			values = append(values, strconv.Quote(elem))
+		}
+		return strings.Join(values, " | ")
+	case mysql.TypeSet: // comma separated values
+		return "string"
+	case mysql.TypeYear:
+		return "number"
+	case mysql.TypeBit:
+		if colTp.Flen == 1 {
+			return "boolean"
+		}
+		return "number"
+	}
+	return "unknown"
+}
+
 func goTypeToProto(fields []tmplField) []tmplField {
 	var newFields []tmplField
 	for _, field := range fields {
@@ -727,6 +779,30 @@ func goTypeToProto(fields []tmplField) []tmplField {
 	return newFields
 }
 
+// split comment into trimmed lines, blank lines are dropped
+func commentLines(comment string) []string {
+	comment = strings.ReplaceAll(comment, "\r\n", "\n")
+	comment = strings.ReplaceAll(comment, "\r", "\n")
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// a comment containing "*/" would end the JSDoc block early
+func jsDocLines(comment string) []string {
+	lines := commentLines(comment)
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "*/", "*\\/")
+	}
+	return lines
+}
+
 func makeTagStr(tags []string) string {
 	builder := strings.Builder{}
 	for i := 0; i < len(tags)/2; i++ {
@@ -803,6 +879,15 @@ func toCamel(s string) string {
 	return n.String()
 }
 
+func inStrings(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func firstLetterToLow(str string) string {
 	if len(str) == 0 {
 		return str
@@ -80,6 +80,206 @@ func TestParseSqls(t *testing.T) {
 	}
 }
 
+func TestParseSqlTypeScript(t *testing.T) {
+	sql := `CREATE TABLE user_order (
+  id BIGINT(11) PRIMARY KEY AUTO_INCREMENT NOT NULL COMMENT 'order id',
+  order_no VARCHAR(30) NOT NULL,
+  paid_at datetime NULL,
+  status ENUM('paid','refund') NOT NULL,
+  tags SET('a','b') NOT NULL,
+  year YEAR NOT NULL,
+  is_deleted BIT(1) NOT NULL,
+  flags BIT(8) NOT NULL,
+  remark varchar(10) DEFAULT NULL,
+  amount int,
+  count int NOT NULL DEFAULT 0
+  ) COMMENT="user order";`
+	codes, err := ParseSQL(sql, WithJSONTag(1))
+	assert.NoError(t, err)
+	code := codes[CodeTypeTypeScript]
+	assert.Contains(t, code, `status: "paid" | "refund";`)
+	assert.Contains(t, code, "tags: string;")
+	assert.Contains(t, code, "year: number;")
+	assert.Contains(t, code, "isDeleted: boolean;")
+	assert.Contains(t, code, "flags: number;")
+	assert.Contains(t, code, "/** user order */\nexport interface UserOrder {")
+	assert.Contains(t, code, "/** order id */\n  id: string;")
+	assert.Contains(t, code, "orderNo: string;")
+	assert.Contains(t, code, "paidAt: string | null;")
+	assert.Contains(t, code, "remark: string | null;")
+	assert.Contains(t, code, "amount: number | null;")
+	assert.Contains(t, code, "count: number;")
+	t.Log(code)
+}
+
+func TestParseSqlMultiLineComment(t *testing.T) {
+	sql := "CREATE TABLE user_order (\n" +
+		"id BIGINT(11) PRIMARY KEY AUTO_INCREMENT NOT NULL COMMENT 'order id',\n" +
+		"status tinyint NOT NULL COMMENT 'status\r\n1: paid\n\n2: refunded'\n" +
+		") COMMENT='user order\ncreated by shop';"
+	codes, err := ParseSQL(sql, WithNoNullType())
+	assert.NoError(t, err)
+
+	assert.Contains(t, codes[CodeTypeModel], "// UserOrder user order\n// created by shop\ntype UserOrder struct {")
+	assert.Contains(t, codes[CodeTypeModel], "\t// status\n\t// 1: paid\n\t// 2: refunded\n\tStatus int")
+	assert.Contains(t, codes[CodeTypeModel], "`gorm:\"column:id;primary_key;AUTO_INCREMENT\"` // order id")
+	assert.Contains(t, codes[CodeTypeHandler], "\t// status\n\t// 1: paid\n\t// 2: refunded\n\tStatus  int")
+	assert.Contains(t, codes[CodeTypeProto], "// user order\n// created by shop\nmessage UserOrder {\n\t// order id\n\tint64 id = 1;")
+	assert.Contains(t, codes[CodeTypeTypeScript], "/**\n * user order\n * created by shop\n */\nexport interface UserOrder {")
+	assert.Contains(t, codes[CodeTypeTypeScript], "  /**\n   * status\n   * 1: paid\n   * 2: refunded\n   */\n  status: number;")
+}
+
+func TestParseSqlEmbedBlankLine(t *testing.T) {
+	sql := "CREATE TABLE user_order (\n" +
+		"id BIGINT(11) PRIMARY KEY AUTO_INCREMENT NOT NULL COMMENT 'order id',\n" +
+		"created_at datetime,\n" +
+		"status tinyint NOT NULL COMMENT 'status'\n" +
+		");"
+	codes, err := ParseSQL(sql, WithEmbed())
+	assert.NoError(t, err)
+	assert.Contains(t, codes[CodeTypeModel], "mysql.Model `gorm:\"embedded\"` // embed id and time\n\n\tStatus int")
+}
+
+func TestParse(t *testing.T) {
+	sql := `CREATE TABLE t_user (
+  id BIGINT(11) PRIMARY KEY AUTO_INCREMENT NOT NULL COMMENT 'user id',
+  name VARCHAR(30) NOT NULL,
+  created_at datetime NOT NULL
+  ) COMMENT="user";
+CREATE TABLE t_user_role (
+  user_id BIGINT(11) unsigned NOT NULL,
+  role_id INT(11) NOT NULL,
+  PRIMARY KEY (user_id, role_id),
+  UNIQUE KEY uk_role (role_id)
+  );`
+	result, err := Parse(sql, WithTablePrefix("t_"))
+	assert.NoError(t, err)
+	assert.Equal(t, "model", result.Package)
+	assert.Equal(t, []string{"time"}, result.ImportPaths)
+	assert.Len(t, result.Tables, 2)
+
+	user := result.Tables[0]
+	assert.Equal(t, "User", user.Table.Name)
+	assert.Equal(t, "t_user", user.Table.RawName)
+	assert.Equal(t, "user", user.Table.Comment)
+	assert.Equal(t, []string{"id"}, user.Table.PrimaryKeys)
+	id := user.Table.Columns[0]
+	assert.Equal(t, "id", id.Name)
+	assert.Equal(t, "ID", id.GoName)
+	assert.Equal(t, "int64", id.GoType)
+	assert.Equal(t, "bigint(11)", id.DBType)
+	assert.Equal(t, "user id", id.Comment)
+	assert.True(t, id.IsPrimaryKey)
+	assert.Equal(t, "time.Time", user.Table.Columns[2].GoType)
+	assert.Contains(t, user.Model, "type User struct")
+	assert.Contains(t, user.Handler, "CreateUserRequest")
+	assert.Contains(t, user.TypeScript, "export interface User {")
+
+	userRole := result.Tables[1]
+	assert.Equal(t, []string{"user_id", "role_id"}, userRole.Table.PrimaryKeys)
+	assert.Equal(t, [][]string{{"role_id"}}, userRole.Table.UniqueKeys)
+	assert.True(t, userRole.Table.Columns[1].IsPrimaryKey)
+	assert.Equal(t, "uint64", userRole.Table.Columns[0].GoType)
+
+	code, ok := userRole.Code(CodeTypeDAO)
+	assert.True(t, ok)
+	assert.Equal(t, userRole.DAO, code)
+	_, ok = userRole.Code("unknown")
+	assert.False(t, ok)
+
+	codes := result.Codes()
+	assert.Equal(t, "TUser, TUserRole", codes[TableName])
+	assert.Equal(t, result.Model, codes[CodeTypeModel])
+	assert.Equal(t, user.Proto+"\n\n"+userRole.Proto, codes[CodeTypeProto])
+}
+
+func TestParseSchema(t *testing.T) {
+	sql := `CREATE TABLE t_user (
+  id BIGINT(11) unsigned PRIMARY KEY AUTO_INCREMENT NOT NULL,
+  u_name VARCHAR(30) NOT NULL DEFAULT 'default_name' COMMENT 'user name',
+  u_email VARCHAR(50) NULL,
+  u_phone VARCHAR(20),
+  created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uk_email (u_email),
+  KEY idx_name_phone (u_name, u_phone)
+  ) COMMENT="user";`
+	schema, err := ParseSchema(sql, WithTablePrefix("t_"), WithColumnPrefix("u_"), WithNullStyle(NullInPointer))
+	assert.NoError(t, err)
+	assert.Len(t, schema.Tables, 1)
+
+	table, ok := schema.Table("t_user")
+	assert.True(t, ok)
+	_, ok = schema.Table("User")
+	assert.True(t, ok)
+	_, ok = schema.Table("order")
+	assert.False(t, ok)
+	assert.Equal(t, "User", table.Name)
+	assert.Equal(t, [][]string{{"u_email"}}, table.UniqueKeys)
+	assert.Equal(t, [][]string{{"u_name", "u_phone"}}, table.Indexes)
+
+	id, _ := table.Column("id")
+	assert.Equal(t, "bigint", id.DataType)
+	assert.True(t, id.IsUnsigned)
+	assert.True(t, id.IsAutoIncrement)
+	assert.False(t, id.IsNullable)
+	assert.Equal(t, "uint64", id.GoType)
+
+	name, _ := table.Column("u_name")
+	assert.Equal(t, "Name", name.GoName)
+	assert.True(t, name.HasDefault)
+	assert.Equal(t, "default_name", name.Default)
+	assert.False(t, name.IsNullable)
+
+	email, _ := table.Column("u_email")
+	assert.True(t, email.IsUnique)
+	assert.True(t, email.IsNullable)
+	assert.Equal(t, "*string", email.GoType)
+
+	phone, _ := table.Column("u_phone")
+	assert.True(t, phone.IsNullable)
+	assert.Equal(t, "string", phone.GoType) // only columns declared with NULL use the null style
+
+	createdAt, _ := table.Column("created_at")
+	assert.Equal(t, "datetime", createdAt.DataType)
+	assert.Equal(t, "CURRENT_TIMESTAMP", createdAt.Default)
+	_, ok = table.Column("deleted_at")
+	assert.False(t, ok)
+
+	result, err := GenerateFromSchema(schema, WithTablePrefix("t_"), WithColumnPrefix("u_"), WithNullStyle(NullInPointer))
+	assert.NoError(t, err)
+	assert.Contains(t, result.Model, "Email     *string")
+	assert.Contains(t, result.Model, "`gorm:\"column:u_email\"`") // table level unique keys are not tagged
+}
+
+func TestParseSQLGormTags(t *testing.T) {
+	sql := `CREATE TABLE user_role (
+  user_id BIGINT(11) unsigned NOT NULL,
+  role_id INT(11) NOT NULL DEFAULT 0,
+  remark VARCHAR(30) NULL UNIQUE,
+  email VARCHAR(50) NOT NULL,
+  PRIMARY KEY (user_id, role_id),
+  UNIQUE KEY uk_email (email)
+  );
+CREATE TABLE orders (
+  id BIGINT AUTO_INCREMENT NOT NULL PRIMARY KEY,
+  name VARCHAR(30) DEFAULT 'x' NOT NULL UNIQUE
+  );`
+	codes, err := ParseSQL(sql)
+	assert.NoError(t, err)
+	model := codes[CodeTypeModel]
+	// only the first column of the primary key is tagged, the options keep the order of the DDL
+	for _, tag := range []string{
+		"`gorm:\"column:user_id;primary_key\"`",
+		"`gorm:\"column:role_id;default:0;NOT NULL\"`",
+		"`gorm:\"column:remark;unique\"`",
+		"`gorm:\"column:email;NOT NULL\"`",
+		"`gorm:\"column:id;AUTO_INCREMENT;primary_key\"`",
+		"`gorm:\"column:name;default:x;unique;NOT NULL\"`",
+	} {
+		assert.Contains(t, model, tag)
+	}
+}
+
 func Test_toCamel(t *testing.T) {
 	str := "user_example"
 	t.Log(toCamel(str))
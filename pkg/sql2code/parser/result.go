@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Result the codes generated from sql, separated by table
+type Result struct {
+	Package     string       // package name of the model code
+	ImportPaths []string     // import paths of the model code
+	Model       string       // model code of all tables, including package clause and imports
+	Tables      []*TableCode // codes of each table, in the order of the sql
+}
+
+// TableCode the codes generated from a table
+type TableCode struct {
+	Table       *Table   // table metadata
+	ImportPaths []string // import paths used by the model struct
+	Model       string   // gorm model struct code
+	JSON        string   // model json code
+	DAO         string   // update fields code
+	Handler     string   // handler request and respond struct code
+	Proto       string   // proto file code
+	Service     string   // grpc service test code
+	TypeScript  string   // typescript interface code
+}
+
+// Code get the code of the specified code type
+func (t *TableCode) Code(codeType string) (string, bool) {
+	switch codeType {
+	case CodeTypeModel:
+		return t.Model, true
+	case CodeTypeJSON:
+		return t.JSON, true
+	case CodeTypeDAO:
+		return t.DAO, true
+	case CodeTypeHandler:
+		return t.Handler, true
+	case CodeTypeProto:
+		return t.Proto, true
+	case CodeTypeService:
+		return t.Service, true
+	case CodeTypeTypeScript:
+		return t.TypeScript, true
+	}
+	return "", false
+}
+
+// Codes join the codes of all tables by code type, the key TableName is the go names of all tables separated by commas
+func (r *Result) Codes() map[string]string {
+	var codesMap = map[string]string{
+		CodeTypeModel: r.Model,
+	}
+	for _, codeType := range []string{CodeTypeJSON, CodeTypeDAO, CodeTypeHandler, CodeTypeProto, CodeTypeService, CodeTypeTypeScript} {
+		codes := make([]string, 0, len(r.Tables))
+		for _, t := range r.Tables {
+			code, _ := t.Code(codeType)
+			codes = append(codes, code)
+		}
+		codesMap[codeType] = strings.Join(codes, "\n\n")
+	}
+
+	tableNames := make([]string, 0, len(r.Tables))
+	for _, t := range r.Tables {
+		tableNames = append(tableNames, toCamel(t.Table.RawName))
+	}
+	codesMap[TableName] = strings.Join(tableNames, ", ")
+
+	return codesMap
+}
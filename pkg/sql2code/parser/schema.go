@@ -0,0 +1,264 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/parser"
+)
+
+// Schema tables parsed from DDL sql or database
+type Schema struct {
+	Tables []*Table
+}
+
+// Table table metadata
+type Table struct {
+	Name        string     // name in go, e.g. UserOrder
+	RawName     string     // name in database, e.g. t_user_order
+	Comment     string     // table comment
+	Columns     []*Column  // columns in the order of the sql
+	PrimaryKeys []string   // primary key column names
+	UniqueKeys  [][]string // column names of each unique key
+	Indexes     [][]string // column names of each normal index
+	Checks      []*Check   // check constraints, only loaded from information_schema
+}
+
+// Check check constraint
+type Check struct {
+	Name   string // constraint name
+	Clause string // check clause, e.g. (age > 0)
+}
+
+// Column column metadata
+type Column struct {
+	Name            string // name in database, e.g. user_id
+	GoName          string // name in go, e.g. UserID
+	GoType          string // type in go, e.g. int64
+	DataType        string // data type in database, e.g. bigint
+	DBType          string // full type in database, e.g. bigint(20) unsigned
+	Comment         string // column comment
+	IsPrimaryKey    bool   // whether the column is part of the primary key
+	IsAutoIncrement bool   // whether the column is auto increment
+	IsUnique        bool   // whether the column has a single column unique key
+	IsUnsigned      bool   // whether the numeric column is unsigned
+	IsNullable      bool   // whether the column can be null
+	HasDefault      bool   // whether the column has a default value
+	Default         string // default value, e.g. CURRENT_TIMESTAMP
+	IsGenerated     bool   // whether the column is a generated column
+	Generation      string // generation expression, only loaded from information_schema
+
+	// column privileges of the current user, e.g. select,insert,update,references, only loaded from information_schema
+	Privileges []string
+
+	tp              *types.FieldType
+	explicitNull    bool     // column declared with NULL, only these columns use the null style
+	isPrimaryKeyTag bool     // the first column of the primary key, tagged primary_key in the gorm tag
+	gormOptions     []string // gorm tag options in the order of the DDL, nil if not loaded from DDL
+}
+
+// Table get the table by raw name or go name
+func (s *Schema) Table(name string) (*Table, bool) {
+	for _, t := range s.Tables {
+		if t.RawName == name || t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Column get the column by name
+func (t *Table) Column(name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// ParseSchema parse the tables in DDL sql
+func ParseSchema(sql string, options ...Option) (*Schema, error) {
+	opt := parseOption(options)
+
+	stmts, err := parser.New().Parse(sql, opt.Charset, opt.Collation)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	for _, stmt := range stmts {
+		if ct, ok := stmt.(*ast.CreateTableStmt); ok {
+			schema.Tables = append(schema.Tables, newTable(ct, opt))
+		}
+	}
+	return schema, nil
+}
+
+func newTable(stmt *ast.CreateTableStmt, opt options) *Table {
+	rawName := stmt.Table.Name.String()
+	table := &Table{
+		Name:    goTableName(rawName, opt),
+		RawName: rawName,
+	}
+
+	// find table comment
+	for _, o := range stmt.Options {
+		if o.Tp == ast.TableOptionComment {
+			table.Comment = o.StrValue
+			break
+		}
+	}
+
+	// only the first column of the primary key constraint is tagged primary_key in the gorm tag
+	firstPrimaryKey := ""
+	for _, con := range stmt.Constraints {
+		if con.Tp == ast.ConstraintPrimaryKey && len(con.Keys) > 0 && firstPrimaryKey == "" {
+			firstPrimaryKey = con.Keys[0].Column.String()
+		}
+
+		var columns []string
+		for _, key := range con.Keys {
+			columns = append(columns, key.Column.Name.String())
+		}
+		switch con.Tp {
+		case ast.ConstraintPrimaryKey:
+			table.PrimaryKeys = append(table.PrimaryKeys, columns...)
+		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			table.UniqueKeys = append(table.UniqueKeys, columns)
+		case ast.ConstraintKey, ast.ConstraintIndex:
+			table.Indexes = append(table.Indexes, columns)
+		}
+	}
+
+	for _, col := range stmt.Cols {
+		column := &Column{
+			Name:         col.Name.Name.String(),
+			DataType:     types.TypeStr(col.Tp.Tp),
+			DBType:       col.Tp.InfoSchemaStr(),
+			IsUnsigned:   mysql.HasUnsignedFlag(col.Tp.Flag),
+			IsPrimaryKey: inStrings(table.PrimaryKeys, col.Name.Name.String()),
+			tp:           col.Tp,
+		}
+
+		isNotNull := false
+		column.isPrimaryKeyTag = column.Name == firstPrimaryKey
+		column.gormOptions = []string{}
+		if column.isPrimaryKeyTag {
+			column.gormOptions = append(column.gormOptions, "primary_key")
+		}
+		for _, o := range col.Options {
+			switch o.Tp {
+			case ast.ColumnOptionPrimaryKey:
+				if !column.IsPrimaryKey {
+					column.IsPrimaryKey = true
+					table.PrimaryKeys = append(table.PrimaryKeys, column.Name)
+				}
+				if !column.isPrimaryKeyTag {
+					column.isPrimaryKeyTag = true
+					column.gormOptions = append(column.gormOptions, "primary_key")
+				}
+			case ast.ColumnOptionNotNull:
+				isNotNull = true
+			case ast.ColumnOptionAutoIncrement:
+				column.IsAutoIncrement = true
+				column.gormOptions = append(column.gormOptions, "AUTO_INCREMENT")
+			case ast.ColumnOptionDefaultValue:
+				if value := getDefaultValue(o.Expr); value != "" {
+					column.HasDefault = true
+					column.Default = value
+					column.gormOptions = append(column.gormOptions, "default:"+value)
+				}
+			case ast.ColumnOptionUniqKey:
+				column.IsUnique = true
+				table.UniqueKeys = append(table.UniqueKeys, []string{column.Name})
+				column.gormOptions = append(column.gormOptions, "unique")
+			case ast.ColumnOptionNull:
+				column.explicitNull = true
+			case ast.ColumnOptionComment:
+				column.Comment = o.Expr.GetDatum().GetString()
+			case ast.ColumnOptionGenerated:
+				column.IsGenerated = true
+			}
+		}
+		if !column.isPrimaryKeyTag && isNotNull {
+			column.gormOptions = append(column.gormOptions, "NOT NULL")
+		}
+		column.IsNullable = !isNotNull && !column.IsPrimaryKey
+		table.Columns = append(table.Columns, column)
+	}
+
+	for _, keys := range table.UniqueKeys {
+		if len(keys) != 1 {
+			continue
+		}
+		if column, ok := table.Column(keys[0]); ok {
+			column.IsUnique = true
+		}
+	}
+
+	for _, column := range table.Columns {
+		setGoField(column, opt)
+	}
+
+	return table
+}
+
+// options of the gorm tag, columns parsed from DDL keep the options in the order of the DDL,
+// other columns use the order of SHOW CREATE TABLE, in which unique keys are table constraints and not tagged
+func (c *Column) gormTagOptions() []string {
+	if c.gormOptions != nil {
+		return c.gormOptions
+	}
+
+	var options []string
+	if c.isPrimaryKeyTag {
+		options = append(options, "primary_key")
+	}
+	if c.IsAutoIncrement {
+		options = append(options, "AUTO_INCREMENT")
+	}
+	if c.HasDefault {
+		options = append(options, "default:"+c.Default)
+	}
+	if !c.isPrimaryKeyTag && !c.IsNullable {
+		options = append(options, "NOT NULL")
+	}
+	return options
+}
+
+func goTableName(rawName string, opt options) string {
+	name := rawName
+	if opt.TablePrefix != "" && strings.HasPrefix(name, opt.TablePrefix) {
+		name = name[len(opt.TablePrefix):]
+	}
+	return toCamel(name)
+}
+
+// set name and type in golang
+func setGoField(column *Column, opt options) {
+	goFieldName := column.Name
+	if opt.ColumnPrefix != "" && strings.HasPrefix(goFieldName, opt.ColumnPrefix) {
+		goFieldName = goFieldName[len(opt.ColumnPrefix):]
+	}
+	column.GoName = toCamel(goFieldName)
+
+	nullStyle := opt.NullStyle
+	if !column.explicitNull {
+		nullStyle = NullDisable
+	}
+	column.GoType, _ = mysqlToGoType(column.tp, nullStyle)
+}
+
+// import path of the go type
+func goTypeImportPath(goType string) string {
+	switch {
+	case strings.Contains(goType, "time."):
+		return "time"
+	case strings.Contains(goType, "sql."):
+		return "database/sql"
+	}
+	return ""
+}
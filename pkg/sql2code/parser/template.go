@@ -8,12 +8,17 @@ import (
 var (
 	modelStructTmpl    *template.Template
 	modelStructTmplRaw = `
-{{- if .Comment -}}
-// {{.TableName}} {{.Comment}}
+{{- range $i, $v := .CommentLines -}}
+// {{if eq $i 0}}{{$.TableName}} {{end}}{{$v}}
 {{end -}}
 type {{.TableName}} struct {
 {{- range .Fields}}
-	{{.Name}} {{.GoType}} {{if .Tag}}` + "`{{.Tag}}`" + `{{end}}{{if .Comment}} // {{.Comment}}{{end}}
+{{- range .LeadingComment}}
+	// {{.}}
+{{- end}}
+	{{.Name}} {{.GoType}} {{if .Tag}}` + "`{{.Tag}}`" + `{{end}}{{with .TrailingComment}} // {{.}}{{end}}
+{{- if .BlankLine}}
+{{end}}
 {{- end}}
 }
 {{if .NameFunc}}
@@ -51,7 +56,10 @@ import (
 // todo fill in the binding rules https://github.com/go-playground/validator
 type Create{{.TableName}}Request struct {
 {{- range .Fields}}
-	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}" binding:""` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- range .LeadingComment}}
+	// {{.}}
+{{- end}}
+	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}" binding:""` + "`" + `{{with .TrailingComment}} // {{.}}{{end}}
 {{- end}}
 }
 `
@@ -61,7 +69,10 @@ type Create{{.TableName}}Request struct {
 // Update{{.TableName}}ByIDRequest update params
 type Update{{.TableName}}ByIDRequest struct {
 {{- range .Fields}}
-	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}" binding:""` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- range .LeadingComment}}
+	// {{.}}
+{{- end}}
+	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}" binding:""` + "`" + `{{with .TrailingComment}} // {{.}}{{end}}
 {{- end}}
 }
 `
@@ -71,7 +82,10 @@ type Update{{.TableName}}ByIDRequest struct {
 // Get{{.TableName}}ByIDRespond respond detail
 type Get{{.TableName}}ByIDRespond struct {
 {{- range .Fields}}
-	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}"` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- range .LeadingComment}}
+	// {{.}}
+{{- end}}
+	{{.Name}}  {{.GoType}} ` + "`" + `json:"{{.ColName}}"` + "`" + `{{with .TrailingComment}} // {{.}}{{end}}
 {{- end}}
 }`
 
@@ -155,21 +169,34 @@ message List{{.TableName}}Reply {
 	protoMessageCreateTmpl    *template.Template
 	protoMessageCreateTmplRaw = `message Create{{.TableName}}Request {
 {{- range $i, $v := .Fields}}
-	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
+{{- range $v.CommentLines}}
+	// {{.}}
+{{- end}}
+	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}};
 {{- end}}
 }`
 
 	protoMessageUpdateTmpl    *template.Template
 	protoMessageUpdateTmplRaw = `message Update{{.TableName}}ByIDRequest {
 {{- range $i, $v := .Fields}}
-	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
+{{- range $v.CommentLines}}
+	// {{.}}
+{{- end}}
+	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}};
 {{- end}}
 }`
 
 	protoMessageDetailTmpl    *template.Template
-	protoMessageDetailTmplRaw = `message {{.TableName}} {
+	protoMessageDetailTmplRaw = `
+{{- range .CommentLines -}}
+// {{.}}
+{{end -}}
+message {{.TableName}} {
 {{- range $i, $v := .Fields}}
-	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
+{{- range $v.CommentLines}}
+	// {{.}}
+{{- end}}
+	{{$v.GoType}} {{$v.ColName}} = {{$v.AddOne $i}};
 {{- end}}
 }`
 
@@ -197,17 +224,42 @@ message List{{.TableName}}Reply {
 	serviceCreateStructTmpl    *template.Template
 	serviceCreateStructTmplRaw = `				return cli.Create(ctx, &pb.Create{{.TableName}}Request{
 					{{- range .Fields}}
-						{{.Name}}:  {{.GoTypeZero}}, {{if .Comment}} // {{.Comment}}{{end}}
+					{{- range .LeadingComment}}
+						// {{.}}
+					{{- end}}
+						{{.Name}}:  {{.GoTypeZero}}, {{with .TrailingComment}} // {{.}}{{end}}
 					{{- end}}
 				})`
 
 	serviceUpdateStructTmpl    *template.Template
 	serviceUpdateStructTmplRaw = `				return cli.UpdateByID(ctx, &pb.Update{{.TableName}}ByIDRequest{
 					{{- range .Fields}}
-						{{.Name}}:  {{.GoTypeZero}}, {{if .Comment}} // {{.Comment}}{{end}}
+					{{- range .LeadingComment}}
+						// {{.}}
+					{{- end}}
+						{{.Name}}:  {{.GoTypeZero}}, {{with .TrailingComment}} // {{.}}{{end}}
 					{{- end}}
 				})`
 
+	tsInterfaceTmpl    *template.Template
+	tsInterfaceTmplRaw = `
+{{- with .JSDocLines -}}
+{{if eq (len .) 1}}/** {{index . 0}} */{{else}}/**{{range .}}
+ * {{.}}{{end}}
+ */{{end}}
+{{end -}}
+export interface {{.TableName}} {
+{{- range .Fields}}
+{{- with .JSDocLines}}
+  {{if eq (len .) 1}}/** {{index . 0}} */{{else}}/**{{range .}}
+   * {{.}}{{end}}
+   */{{end}}
+{{- end}}
+  {{.JSONName}}: {{.TSType}}{{if .Nullable}} | null{{end}};
+{{- end}}
+}
+`
+
 	tmplParseOnce sync.Once
 )
 
@@ -270,5 +322,9 @@ func initTemplate() {
 		if err != nil {
 			panic(err)
 		}
+		tsInterfaceTmpl, err = template.New("tsInterface").Parse(tsInterfaceTmplRaw)
+		if err != nil {
+			panic(err)
+		}
 	})
 }
@@ -1,9 +1,11 @@
 package sql2code
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/zhufuyi/gotool/pkg/sql2code/parser"
 )
@@ -14,15 +16,17 @@ type Args struct {
 
 	DDLFile string // 读取文件的DDL sql
 
-	DBDsn   string // 从db获取表的DDL sql
-	DBTable string
+	DBDsn     string        // 从db获取表的DDL sql
+	DBTable   string        // 表名
+	DBLoader  string        // 从db获取表结构方式，ddl(默认，使用SHOW CREATE TABLE)或information_schema
+	DBTimeout time.Duration // 从db获取表结构超时时间，0表示不限制
 
 	Package        string // 生成字段的包名(只有model类型有效)
 	GormType       bool   // 是否显示gorm type名称(只有model类型代码有效)
 	JSONTag        bool   // 是否包括json tag
 	JSONNamedType  int    // json命名类型，0:和列名一致，其他值表示驼峰
 	IsEmbed        bool   // 是否嵌入gorm.Model
-	CodeType       string // 指定生成代码用途，支持4中类型，分别是 model(默认), json, dao, handler
+	CodeType       string // 指定生成代码用途，支持model(默认), json, dao, handler, proto, service, typescript
 	ForceTableName bool
 	Charset        string
 	Collation      string
@@ -32,14 +36,31 @@ type Args struct {
 	NullStyle      string
 }
 
+const (
+	// DBLoaderDDL 使用SHOW CREATE TABLE获取表结构
+	DBLoaderDDL = "ddl"
+	// DBLoaderInformationSchema 从information_schema获取表结构
+	DBLoaderInformationSchema = "information_schema"
+)
+
 func (a *Args) checkValid() error {
 	if a.SQL == "" && a.DDLFile == "" && (a.DBDsn == "" && a.DBTable == "") {
 		return errors.New("you must specify sql or ddl file")
 	}
+	switch a.DBLoader {
+	case "", DBLoaderDDL, DBLoaderInformationSchema:
+	default:
+		return fmt.Errorf("unknown db loader %s, must be ddl or information_schema", a.DBLoader)
+	}
 	return nil
 }
 
-func getSQL(args *Args) (string, error) {
+// 是否从information_schema获取表结构
+func (a *Args) isInformationSchema() bool {
+	return a.SQL == "" && a.DDLFile == "" && a.DBDsn != "" && a.DBLoader == DBLoaderInformationSchema
+}
+
+func getSQL(ctx context.Context, args *Args) (string, error) {
 	if args.SQL != "" {
 		return args.SQL, nil
 	}
@@ -55,7 +76,7 @@ func getSQL(args *Args) (string, error) {
 		if args.DBTable == "" {
 			return sql, errors.New("miss mysql table")
 		}
-		sqlStr, err := parser.GetCreateTableFromDB(args.DBDsn, args.DBTable)
+		sqlStr, err := parser.GetCreateTableFromDBContext(ctx, args.DBDsn, args.DBTable)
 		if err != nil {
 			return sql, fmt.Errorf("get create table error: %s", err)
 		}
@@ -134,18 +155,49 @@ func GenerateOne(args *Args) (string, error) {
 	return out, nil
 }
 
-// Generate 生成model, json, dao, handler不同用途代码
+// Generate 生成model, json, dao, handler, proto, service, typescript不同用途代码
 func Generate(args *Args) (map[string]string, error) {
-	if err := args.checkValid(); err != nil {
+	result, err := GenerateResult(args)
+	if err != nil {
 		return nil, err
 	}
+	return result.Codes(), nil
+}
 
-	sql, err := getSQL(args)
-	if err != nil {
+// GenerateResult 生成不同用途代码，按表区分代码，并包括表的元数据
+func GenerateResult(args *Args) (*parser.Result, error) {
+	return GenerateResultWithContext(context.Background(), args)
+}
+
+// GenerateResultWithContext 生成不同用途代码，从db获取表结构时，ctx结束或超过args.DBTimeout会取消查询
+func GenerateResultWithContext(ctx context.Context, args *Args) (*parser.Result, error) {
+	if err := args.checkValid(); err != nil {
 		return nil, err
 	}
 
+	if args.DBTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.DBTimeout)
+		defer cancel()
+	}
+
 	opt := getOptions(args)
 
-	return parser.ParseSQL(sql, opt...)
+	if args.isInformationSchema() {
+		if args.DBTable == "" {
+			return nil, errors.New("miss mysql table")
+		}
+		schema, err := parser.GetSchemaFromInformationSchema(ctx, args.DBDsn, []string{args.DBTable}, opt...)
+		if err != nil {
+			return nil, fmt.Errorf("get table schema error: %s", err)
+		}
+		return parser.GenerateFromSchema(schema, opt...)
+	}
+
+	sql, err := getSQL(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.Parse(sql, opt...)
 }
@@ -103,3 +103,51 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateResult(t *testing.T) {
+	result, err := GenerateResult(&Args{SQL: sqlData, JSONTag: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tables) != 1 {
+		t.Fatalf("got %d tables, want 1", len(result.Tables))
+	}
+
+	table := result.Tables[0].Table
+	if table.Name != "User" || table.RawName != "user" {
+		t.Errorf("got table name %s(%s)", table.Name, table.RawName)
+	}
+	if len(table.Columns) != 10 {
+		t.Errorf("got %d columns, want 10", len(table.Columns))
+	}
+	if !table.Columns[0].IsPrimaryKey || table.Columns[0].GoType != "uint64" {
+		t.Errorf("column id = %+v", table.Columns[0])
+	}
+	if len(table.UniqueKeys) != 1 || table.UniqueKeys[0][0] != "email" {
+		t.Errorf("got unique keys %v", table.UniqueKeys)
+	}
+	t.Log(result.Tables[0].Model, result.ImportPaths)
+}
+
+func TestGenerateResultDBLoader(t *testing.T) {
+	_, err := GenerateResult(&Args{DBDsn: "root:123456@(127.0.0.1:3306)/test", DBTable: "user", DBLoader: "unknown"})
+	if err == nil {
+		t.Error("expected error for unknown db loader")
+	}
+
+	_, err = GenerateResult(&Args{DBDsn: "root:123456@(127.0.0.1:3306)/test", DBLoader: DBLoaderInformationSchema})
+	if err == nil {
+		t.Error("expected error for missing table")
+	}
+
+	//result, err := GenerateResult(&Args{
+	//	DBDsn:     "root:123456@(127.0.0.1:3306)/test",
+	//	DBTable:   "user",
+	//	DBLoader:  DBLoaderInformationSchema,
+	//	DBTimeout: 10 * time.Second,
+	//})
+	//if err != nil {
+	//	t.Fatal(err)
+	//}
+	//t.Log(result.Model)
+}